@@ -0,0 +1,171 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client
+const exportFlushEvery = 100
+
+// activityExportColumns is the stable column set of the activities export
+var activityExportColumns = []string{
+	"id",
+	"created_at",
+	"user_id",
+	"user_email",
+	"action",
+	"entity_type",
+	"entity_id",
+	"details",
+	"ip_address",
+	"user_agent",
+}
+
+// ExportActivitiesAction streams the activity logs of a period as CSV or NDJSON.
+// The export is ordered by ID so an interrupted download can be resumed by
+// passing the last received ID as the cursor.
+func ExportActivitiesAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Export activities endpoint called")
+
+	from, to, ok := statsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Format must be csv or ndjson")
+		return
+	}
+
+	var cursor int64
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Cursor must be a positive activity ID")
+			return
+		}
+		cursor = parsed
+	}
+
+	filename := fmt.Sprintf(
+		"activities-%s-%s.%s",
+		from.Format(statsDateLayout),
+		to.Format(statsDateLayout),
+		format,
+	)
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "ndjson" {
+		contentType = "application/x-ndjson"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	csvWriter := csv.NewWriter(w)
+	encoder := json.NewEncoder(w)
+	rows := 0
+
+	if format == "csv" {
+		csvWriter.Write(activityExportColumns)
+	}
+
+	// The to date is inclusive so the period ends at the start of the next day
+	err := db.NewActivityRepository(db.GetDB()).EachByDateRange(from, to.AddDate(0, 0, 1), cursor, func(activity *db.Activity) error {
+		var err error
+		if format == "csv" {
+			err = csvWriter.Write(activityRecord(activity))
+		} else {
+			err = encoder.Encode(activityObject(activity))
+		}
+		if err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			csvWriter.Flush()
+			controller.Flush()
+		}
+		return csvWriter.Error()
+	})
+
+	csvWriter.Flush()
+
+	// Headers are already sent, so a failure can only be logged and the
+	// client resumes with the cursor of the last row it received
+	if err != nil {
+		log.Error().Err(err).Int("rows", rows).Msg("Failed to export activities")
+		return
+	}
+
+	log.Info().Int("rows", rows).Str("format", format).Msg("Activities exported")
+}
+
+// activityRecord returns the CSV record of an activity
+func activityRecord(activity *db.Activity) []string {
+	return []string{
+		strconv.FormatInt(activity.ID, 10),
+		activity.CreatedAt.UTC().Format(time.RFC3339),
+		optionalInt(activity.UserID),
+		optionalString(activity.UserEmail),
+		activity.Action,
+		activity.EntityType,
+		optionalInt(activity.EntityID),
+		optionalString(activity.Details),
+		optionalString(activity.IPAddress),
+		optionalString(activity.UserAgent),
+	}
+}
+
+// activityObject returns the NDJSON object of an activity
+func activityObject(activity *db.Activity) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          activity.ID,
+		"created_at":  activity.CreatedAt.UTC().Format(time.RFC3339),
+		"user_id":     activity.UserID,
+		"user_email":  activity.UserEmail,
+		"action":      activity.Action,
+		"entity_type": activity.EntityType,
+		"entity_id":   activity.EntityID,
+		"details":     activity.Details,
+		"ip_address":  activity.IPAddress,
+		"user_agent":  activity.UserAgent,
+	}
+}
+
+// optionalInt formats a nullable integer, empty when it is null
+func optionalInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+// optionalString formats a nullable string, empty when it is null
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
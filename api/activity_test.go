@@ -0,0 +1,94 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationExportActivities tests the activities export endpoint
+func TestIntegrationExportActivities(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_export_activities.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	_, err := db.GetDB().Exec(`
+		CREATE TABLE activities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			user_email VARCHAR(255),
+			action VARCHAR(100) NOT NULL,
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id INTEGER,
+			details TEXT,
+			ip_address VARCHAR(45),
+			user_agent VARCHAR(500),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.GetDB().Exec(`INSERT INTO activities (user_id, user_email, action, entity_type, created_at) VALUES
+		(1, 'admin@example.com', 'user.deactivate', 'user', '2025-02-01 10:00:00'),
+		(NULL, NULL, 'file.uploaded', 'file', '2025-02-02 10:00:00'),
+		(1, 'admin@example.com', 'file.deleted', 'file', '2025-03-02 10:00:00')`)
+	require.NoError(t, err)
+
+	t.Run("CSV export", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/action/activities/export?from=2025-02-01&to=2025-02-28", nil)
+		w := httptest.NewRecorder()
+
+		ExportActivitiesAction(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="activities-2025-02-01-2025-02-28.csv"`, w.Header().Get("Content-Disposition"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, strings.Join(activityExportColumns, ","), lines[0])
+		assert.Equal(t, "1,2025-02-01T10:00:00Z,1,admin@example.com,user.deactivate,user,,,,", lines[1])
+		assert.Equal(t, "2,2025-02-02T10:00:00Z,,,file.uploaded,file,,,,", lines[2])
+	})
+
+	t.Run("NDJSON export resumes after the cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/action/activities/export?from=2025-02-01&to=2025-03-31&format=ndjson&cursor=1", nil)
+		w := httptest.NewRecorder()
+
+		ExportActivitiesAction(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+		assert.Equal(t, float64(3), row["id"])
+		assert.Equal(t, "file.deleted", row["action"])
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/action/activities/export?format=xml", nil)
+		w := httptest.NewRecorder()
+
+		ExportActivitiesAction(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// AppVersion is recorded in the manifest of backups created through the API
+var AppVersion = "dev"
+
+// CreateBackupAction handles backup creation requests
+func CreateBackupAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Create backup endpoint called")
+
+	backup, err := newBackupManager().CreateBackupInDir()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create backup")
+		service.WriteInternalError(w, "Failed to create backup")
+		return
+	}
+
+	log.Info().Str("filename", backup.Name).Int64("size", backup.Size).Msg("Backup created successfully")
+	service.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"successMessage": "Backup created successfully",
+		"filename":       backup.Name,
+		"size":           backup.Size,
+	})
+}
+
+// ListBackupsAction handles backup listing requests
+func ListBackupsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("List backups endpoint called")
+
+	backups, err := newBackupManager().ListBackups()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list backups")
+		service.WriteInternalError(w, "Failed to list backups")
+		return
+	}
+
+	backupList := make([]map[string]interface{}, 0, len(backups))
+	for _, backup := range backups {
+		backupList = append(backupList, map[string]interface{}{
+			"filename":  backup.Name,
+			"size":      backup.Size,
+			"createdAt": backup.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"backups": backupList,
+	})
+}
+
+// DownloadBackupAction handles backup download requests
+func DownloadBackupAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Download backup endpoint called")
+
+	filename := chi.URLParam(r, "filename")
+
+	path, err := newBackupManager().GetBackupPath(filename)
+	if err != nil {
+		if errors.Is(err, service.ErrBackupNotFound) {
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "Backup not found")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to get backup")
+		service.WriteInternalError(w, "Failed to get backup")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeFile(w, r, path)
+}
+
+// newBackupManager creates a backup manager from the configuration
+func newBackupManager() *service.BackupManager {
+	return service.NewBackupManager(
+		db.GetDB(),
+		service.BackupConfig{
+			Driver:     viper.GetString("app.database.driver"),
+			Host:       viper.GetString("app.database.host"),
+			Port:       viper.GetInt("app.database.port"),
+			Username:   viper.GetString("app.database.username"),
+			Password:   viper.GetString("app.database.password"),
+			Database:   viper.GetString("app.database.name"),
+			DataSource: viper.GetString("app.database.datasource"),
+			AppVersion: AppVersion,
+		},
+		viper.GetString("app.backup.dir"),
+	)
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// BatchPath is the path of the batch endpoint, sub-requests can not target it
+const BatchPath = "/api/v1/action/batch"
+
+// MaxBatchRequests is the maximum number of sub-requests in a batch
+const MaxBatchRequests = 20
+
+// batchAuthHeaders are copied from the batch request to each sub-request
+var batchAuthHeaders = []string{"Cookie", "X-API-Key", "User-Agent", "X-Forwarded-For", "X-Real-IP"}
+
+// BatchSubRequest represents a single request in a batch
+type BatchSubRequest struct {
+	Method string          `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE" label:"Method"`
+	Path   string          `json:"path" validate:"required,startswith=/api/,max=2048" label:"Path"`
+	Body   json.RawMessage `json:"body,omitempty" label:"Body"`
+}
+
+// BatchRequest represents the batch request payload
+type BatchRequest struct {
+	Requests []BatchSubRequest `json:"requests" validate:"required,min=1,max=20,dive" label:"Requests"`
+}
+
+// BatchAction returns a handler that replays each sub-request against the
+// router and collects the responses in order. Sub-requests carry the
+// credentials of the batch request so they are authenticated and authorized
+// like standalone requests.
+func BatchAction(router http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Debug().Msg("Batch endpoint called")
+
+		if _, ok := middleware.GetUserFromContext(r.Context()); !ok {
+			service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+			return
+		}
+
+		var req BatchRequest
+		if err := service.DecodeAndValidate(r, &req); err != nil {
+			service.WriteValidationError(w, err)
+			return
+		}
+
+		for _, sub := range req.Requests {
+			if strings.HasPrefix(sub.Path, BatchPath) {
+				service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Batch requests can not be nested")
+				return
+			}
+		}
+
+		responses := make([]map[string]interface{}, 0, len(req.Requests))
+		for _, sub := range req.Requests {
+			responses = append(responses, replayBatchRequest(router, r, sub))
+		}
+
+		service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"responses": responses,
+		})
+	}
+}
+
+// replayBatchRequest runs a sub-request against the router and returns its
+// response, a path that is not a valid request URI gets a 400 of its own
+func replayBatchRequest(router http.Handler, parent *http.Request, sub BatchSubRequest) map[string]interface{} {
+	subReq, err := newBatchSubRequest(parent, sub)
+	if err != nil {
+		return map[string]interface{}{
+			"status": http.StatusBadRequest,
+			"body":   service.NewAPIError(service.ErrorCodeBadRequest, "Invalid request path"),
+		}
+	}
+	for _, header := range batchAuthHeaders {
+		if value := parent.Header.Get(header); value != "" {
+			subReq.Header.Set(header, value)
+		}
+	}
+	if len(sub.Body) > 0 {
+		subReq.Header.Set("Content-Type", "application/json")
+	}
+
+	recorder := httptest.NewRecorder()
+	if !serveBatchRequest(router, recorder, subReq) {
+		return map[string]interface{}{
+			"status": http.StatusInternalServerError,
+			"body":   service.NewAPIError(service.ErrorCodeInternal, "Internal server error"),
+		}
+	}
+
+	response := map[string]interface{}{
+		"status": recorder.Code,
+		"body":   nil,
+	}
+
+	body := bytes.TrimSpace(recorder.Body.Bytes())
+	if len(body) == 0 {
+		return response
+	}
+
+	if json.Valid(body) {
+		response["body"] = json.RawMessage(body)
+	} else {
+		response["body"] = string(body)
+	}
+
+	return response
+}
+
+// serveBatchRequest runs a sub-request, it returns false when the handler
+// panicked after writing its response. A standalone request would have its
+// connection aborted, here only the sub-request fails.
+func serveBatchRequest(router http.Handler, w http.ResponseWriter, r *http.Request) (completed bool) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if err, ok := rec.(error); !ok || !errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			completed = false
+		}
+	}()
+
+	router.ServeHTTP(w, r)
+	return true
+}
+
+// newBatchSubRequest builds the request of a sub-request. Unlike a request
+// read from the wire, the path is client supplied JSON and may contain
+// characters no HTTP request line can carry.
+func newBatchSubRequest(parent *http.Request, sub BatchSubRequest) (*http.Request, error) {
+	if strings.ContainsAny(sub.Path, " \t") {
+		return nil, fmt.Errorf("invalid request path %q", sub.Path)
+	}
+
+	// Drop the chi route context of the batch request so the router
+	// resolves the sub-request path from scratch
+	ctx := context.WithValue(parent.Context(), chi.RouteCtxKey, nil)
+
+	subReq, err := http.NewRequestWithContext(ctx, sub.Method, sub.Path, bytes.NewReader(sub.Body))
+	if err != nil {
+		return nil, err
+	}
+	subReq.Host = parent.Host
+	subReq.RequestURI = sub.Path
+	subReq.RemoteAddr = parent.RemoteAddr
+
+	return subReq, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchTestRouter returns a router that authenticates requests carrying
+// the test API key and echoes the item ID and request body
+func newBatchTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-API-Key") != "test-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), middleware.ContextKeyUser, &db.User{ID: 1})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	r.Get("/api/v1/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":%q}`, chi.URLParam(r, "id"))
+	})
+	r.Post("/api/v1/items", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	r.Get("/api/v1/broken", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+	r.Get("/api/v1/text", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("plain"))
+	})
+	r.Post(BatchPath, BatchAction(r))
+	return r
+}
+
+func batchRequest(t *testing.T, router http.Handler, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(body))
+	req.Header.Set("X-API-Key", "test-key")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+// TestUnitBatchAction tests replaying sub-requests against the router
+func TestUnitBatchAction(t *testing.T) {
+	router := newBatchTestRouter()
+
+	t.Run("Replays sub-requests in order", func(t *testing.T) {
+		status, response := batchRequest(t, router, `{"requests":[
+			{"method":"GET","path":"/api/v1/items/5"},
+			{"method":"POST","path":"/api/v1/items","body":{"name":"item"}},
+			{"method":"GET","path":"/api/v1/text"},
+			{"method":"GET","path":"/api/v1/missing"}
+		]}`)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"status": float64(200), "body": map[string]interface{}{"id": "5"}},
+			map[string]interface{}{"status": float64(201), "body": map[string]interface{}{"name": "item"}},
+			map[string]interface{}{"status": float64(200), "body": "plain"},
+			map[string]interface{}{"status": float64(404), "body": "404 page not found"},
+		}, response["responses"])
+	})
+
+	t.Run("Rejects nested batches", func(t *testing.T) {
+		status, response := batchRequest(t, router, `{"requests":[{"method":"POST","path":"/api/v1/action/batch"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "bad_request", response["errorCode"])
+	})
+
+	t.Run("Rejects more than the maximum sub-requests", func(t *testing.T) {
+		requests := make([]string, MaxBatchRequests+1)
+		for i := range requests {
+			requests[i] = `{"method":"GET","path":"/api/v1/items/1"}`
+		}
+		status, response := batchRequest(t, router, `{"requests":[`+strings.Join(requests, ",")+`]}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "validation_failed", response["errorCode"])
+	})
+
+	t.Run("Malformed paths fail on their own", func(t *testing.T) {
+		status, response := batchRequest(t, router, `{"requests":[
+			{"method":"GET","path":"/api/%zz"},
+			{"method":"GET","path":"/api/a b"},
+			{"method":"GET","path":"/api/v1/items/7"}
+		]}`)
+
+		assert.Equal(t, http.StatusOK, status)
+
+		responses := response["responses"].([]interface{})
+		require.Len(t, responses, 3)
+		for _, item := range responses[:2] {
+			sub := item.(map[string]interface{})
+			assert.Equal(t, float64(http.StatusBadRequest), sub["status"])
+			assert.Equal(t, "bad_request", sub["body"].(map[string]interface{})["errorCode"])
+		}
+		assert.Equal(t, float64(http.StatusOK), responses[2].(map[string]interface{})["status"])
+	})
+
+	t.Run("Aborted sub-requests fail on their own", func(t *testing.T) {
+		status, response := batchRequest(t, router, `{"requests":[
+			{"method":"GET","path":"/api/v1/broken"},
+			{"method":"GET","path":"/api/v1/items/8"}
+		]}`)
+
+		assert.Equal(t, http.StatusOK, status)
+
+		responses := response["responses"].([]interface{})
+		require.Len(t, responses, 2)
+		assert.Equal(t, float64(http.StatusInternalServerError), responses[0].(map[string]interface{})["status"])
+		assert.Equal(t, float64(http.StatusOK), responses[1].(map[string]interface{})["status"])
+	})
+
+	t.Run("Rejects paths outside the API", func(t *testing.T) {
+		status, response := batchRequest(t, router, `{"requests":[{"method":"GET","path":"/assets/app.js"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "validation_failed", response["errorCode"])
+	})
+}
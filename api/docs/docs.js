@@ -0,0 +1,130 @@
+// Renders the OpenAPI document of the Tut API without any external script
+;(() => {
+  const root = document.getElementById('docs')
+
+  const el = (tag, className, text) => {
+    const node = document.createElement(tag)
+    if (className) {
+      node.className = className
+    }
+    if (text !== undefined && text !== null) {
+      node.textContent = String(text)
+    }
+    return node
+  }
+
+  const table = (headers, rows) => {
+    const result = el('table')
+    const head = el('tr')
+    headers.forEach((header) => head.appendChild(el('th', null, header)))
+    result.appendChild(head)
+    rows.forEach((row) => {
+      const tr = el('tr')
+      row.forEach((cell) => tr.appendChild(el('td', null, cell)))
+      result.appendChild(tr)
+    })
+    return result
+  }
+
+  const resolve = (spec, schema) => {
+    if (!schema || !schema.$ref) {
+      return schema || {}
+    }
+    const name = schema.$ref.split('/').pop()
+    return spec.components.schemas?.[name] || {}
+  }
+
+  const constraints = (schema) => {
+    const keys = ['format', 'minLength', 'maxLength', 'minimum', 'maximum', 'minItems', 'maxItems']
+    const parts = keys.filter((key) => schema[key] !== undefined).map((key) => `${key}: ${schema[key]}`)
+    if (schema.enum) {
+      parts.push(`one of: ${schema.enum.join(', ')}`)
+    }
+    if (schema.description) {
+      parts.push(schema.description)
+    }
+    return parts.join('; ')
+  }
+
+  const renderOperation = (spec, method, path, operation) => {
+    const section = el('div', 'operation')
+    const title = el('div', 'operation-title')
+    title.appendChild(el('span', `method method-${method}`, method.toUpperCase()))
+    title.appendChild(el('code', 'path', path))
+    if (operation.security?.length === 0) {
+      title.appendChild(el('span', 'badge', 'Public'))
+    }
+    section.appendChild(title)
+    section.appendChild(el('p', 'summary', operation.summary))
+
+    if (operation.parameters?.length > 0) {
+      section.appendChild(el('h4', null, 'Parameters'))
+      section.appendChild(
+        table(
+          ['Name', 'In', 'Type', 'Required', 'Description'],
+          operation.parameters.map((parameter) => [
+            parameter.name,
+            parameter.in,
+            parameter.schema?.type,
+            parameter.required ? 'yes' : 'no',
+            parameter.description || '',
+          ])
+        )
+      )
+    }
+
+    if (operation.requestBody) {
+      const schema = resolve(spec, operation.requestBody.content['application/json'].schema)
+      const required = schema.required || []
+      section.appendChild(el('h4', null, 'Request body'))
+      section.appendChild(
+        table(
+          ['Field', 'Type', 'Required', 'Constraints'],
+          Object.keys(schema.properties || {})
+            .sort()
+            .map((name) => {
+              const property = schema.properties[name]
+              return [name, property.type, required.includes(name) ? 'yes' : 'no', constraints(property)]
+            })
+        )
+      )
+    }
+
+    return section
+  }
+
+  const render = (spec) => {
+    root.textContent = ''
+    root.appendChild(el('h1', null, `${spec.info.title} ${spec.info.version}`))
+    root.appendChild(el('p', null, 'Authenticate with the X-API-Key header or a session cookie unless an operation is public.'))
+
+    // Tags keep the order of the first operation using them
+    const operations = new Map()
+    Object.entries(spec.paths).forEach(([path, item]) => {
+      Object.entries(item).forEach(([method, operation]) => {
+        const tag = operation.tags?.[0] || 'Other'
+        if (!operations.has(tag)) {
+          operations.set(tag, [])
+        }
+        operations.get(tag).push(renderOperation(spec, method, path, operation))
+      })
+    })
+
+    operations.forEach((nodes, tag) => {
+      root.appendChild(el('h2', null, tag))
+      nodes.forEach((node) => root.appendChild(node))
+    })
+  }
+
+  fetch('/api/v1/openapi.json', { credentials: 'same-origin' })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error('Failed to load the OpenAPI document')
+      }
+      return response.json()
+    })
+    .then(render)
+    .catch((error) => {
+      root.textContent = error.message
+    })
+})()
@@ -5,7 +5,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/clivern/tut/db"
@@ -33,37 +35,105 @@ func LoginAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userRepo := db.NewUserRepository(db.GetDB())
-	sessionRepo := db.NewSessionRepository(db.GetDB())
-	authModule := module.NewAuth(userRepo)
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	localLoginEnabled, err := settingsModule.IsLocalLoginEnabled()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check if password login is enabled")
+		service.WriteInternalError(w, "Failed to login")
+		return
+	}
+
+	if !localLoginEnabled {
+		service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "Password login is disabled, please use single sign-on")
+		return
+	}
+
+	authModule := module.NewAuth(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+	if viper.IsSet("app.security.login_max_attempts") {
+		authModule.MaxFailedLogins = viper.GetInt("app.security.login_max_attempts")
+	}
+	if minutes := viper.GetInt("app.security.login_lockout_minutes"); minutes > 0 {
+		authModule.LockoutDuration = time.Duration(minutes) * time.Minute
+	}
 
 	user, err := authModule.Login(req.Email, req.Password)
-	if err != nil {
-		service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-			"errorMessage": "Invalid credentials",
+
+	var lockedErr *module.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter := int(time.Until(lockedErr.Until).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		service.WriteErrorWithDetails(w, http.StatusTooManyRequests, service.ErrorCodeAccountLocked, "Too many failed logins, please try again later", map[string]interface{}{
+			"lockedUntil": lockedErr.Until.UTC().Format(time.RFC3339),
 		})
 		return
 	}
+	if err != nil {
+		if !errors.Is(err, module.ErrInvalidCredentials) {
+			log.Error().Err(err).Msg("Failed to check credentials")
+		}
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeInvalidCredentials, "Invalid credentials")
+		return
+	}
 
 	if !user.IsActive {
-		service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-			"errorMessage": "User is not active",
-		})
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeAccountInactive, "User is not active")
+		return
+	}
+
+	if err := startSession(w, r, user, req.RememberMe); err != nil {
+		service.WriteInternalError(w, "Failed to create session")
 		return
 	}
 
-	sessionManager := module.NewSessionManager(sessionRepo, userRepo)
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Login successful",
+		"user": map[string]interface{}{
+			"id":          user.ID,
+			"email":       user.Email,
+			"role":        user.Role,
+			"isActive":    user.IsActive,
+			"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
+			"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
+			"updatedAt":   user.UpdatedAt.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// LoginOptionsAction returns the login methods available to users
+func LoginOptionsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Login options endpoint called")
+
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	settings, err := settingsModule.GetOIDCSettings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get login options")
+		service.WriteInternalError(w, "Failed to get login options")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"localLoginEnabled": settings.LocalLoginEnabled,
+		"ssoEnabled":        settings.Enabled,
+	})
+}
+
+// startSession creates a session for the user and sets the session cookie
+func startSession(w http.ResponseWriter, r *http.Request, user *db.User, rememberMe bool) error {
+	sessionManager := module.NewSessionManager(
+		db.NewSessionRepository(db.GetDB()),
+		db.NewUserRepository(db.GetDB()),
+	)
 	session, err := sessionManager.CreateSession(
 		user.ID,
 		time.Hour*24*7,
-		r.RemoteAddr,
+		service.ClientIP(r),
 		r.UserAgent(),
 	)
 	if err != nil {
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to create session",
-		})
-		return
+		return err
 	}
 
 	var cookieOptions *service.CookieOptions
@@ -72,23 +142,12 @@ func LoginAction(w http.ResponseWriter, r *http.Request) {
 	} else {
 		cookieOptions = service.DefaultCookieOptions()
 	}
-	if req.RememberMe {
+	if rememberMe {
 		cookieOptions.MaxAge = int((time.Hour * 24 * 30) / time.Second)
 	} else {
 		cookieOptions.MaxAge = 0
 	}
 
 	service.SetCookie(w, "_tut_session", session.Token, cookieOptions)
-	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"successMessage": "Login successful",
-		"user": map[string]interface{}{
-			"id":          user.ID,
-			"email":       user.Email,
-			"role":        user.Role,
-			"isActive":    user.IsActive,
-			"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
-			"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
-			"updatedAt":   user.UpdatedAt.UTC().Format(time.RFC3339),
-		},
-	})
+	return nil
 }
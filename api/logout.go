@@ -21,9 +21,7 @@ func LogoutAction(w http.ResponseWriter, r *http.Request) {
 
 	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
-		service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-			"errorMessage": "Not authenticated",
-		})
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
 		return
 	}
 
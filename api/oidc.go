@@ -0,0 +1,163 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+// Cookies holding the single sign-on flow state between login and callback
+const (
+	oidcStateCookie    = "_tut_oidc_state"
+	oidcNonceCookie    = "_tut_oidc_nonce"
+	oidcVerifierCookie = "_tut_oidc_verifier"
+)
+
+// OIDCLoginAction redirects the user to the identity provider
+func OIDCLoginAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("OIDC login endpoint called")
+
+	oidcModule, err := newOIDCModule()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get single sign-on settings")
+		service.WriteInternalError(w, "Failed to start single sign-on")
+		return
+	}
+
+	state := uuid.New().String()
+	nonce := uuid.New().String()
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := oidcModule.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		if errors.Is(err, module.ErrOIDCDisabled) {
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeSSODisabled, "Single sign-on is not enabled")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to start single sign-on")
+		service.WriteInternalError(w, "Failed to start single sign-on")
+		return
+	}
+
+	cookieOptions := oidcCookieOptions()
+	service.SetCookie(w, oidcStateCookie, state, cookieOptions)
+	service.SetCookie(w, oidcNonceCookie, nonce, cookieOptions)
+	service.SetCookie(w, oidcVerifierCookie, verifier, cookieOptions)
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallbackAction completes the single sign-on login
+func OIDCCallbackAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("OIDC callback endpoint called")
+
+	state := service.GetCookie(r, oidcStateCookie)
+	nonce := service.GetCookie(r, oidcNonceCookie)
+	verifier := service.GetCookie(r, oidcVerifierCookie)
+
+	service.DeleteCookie(w, oidcStateCookie)
+	service.DeleteCookie(w, oidcNonceCookie)
+	service.DeleteCookie(w, oidcVerifierCookie)
+
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		log.Info().Str("error", query.Get("error")).Msg("Identity provider returned an error")
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Single sign-on failed")
+		return
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid single sign-on state")
+		return
+	}
+
+	oidcModule, err := newOIDCModule()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get single sign-on settings")
+		service.WriteInternalError(w, "Single sign-on failed")
+		return
+	}
+
+	identity, err := oidcModule.Exchange(r.Context(), query.Get("code"), verifier, nonce)
+	if err != nil {
+		log.Info().Err(err).Msg("Single sign-on token exchange failed")
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Single sign-on failed")
+		return
+	}
+
+	// Linking an existing account rewrites its password and metadata
+	var user *db.User
+	err = db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		var err error
+		user, err = module.NewOIDC(tx.Users, tx.UsersMeta, oidcModule.Settings).ResolveUser(identity)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, module.ErrOIDCEmailNotVerified) || errors.Is(err, module.ErrOIDCMissingEmail) {
+			service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "A verified email is required for single sign-on")
+			return
+		}
+		if errors.Is(err, module.ErrOIDCAccountPending) {
+			service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "An account with this email is pending verification")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to resolve single sign-on user")
+		service.WriteInternalError(w, "Single sign-on failed")
+		return
+	}
+
+	if !user.IsActive {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeAccountInactive, "User is not active")
+		return
+	}
+
+	if err := db.NewUserRepository(db.GetDB()).UpdateLastLogin(user.ID); err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to update last login")
+	}
+
+	if err := startSession(w, r, user, false); err != nil {
+		service.WriteInternalError(w, "Failed to create session")
+		return
+	}
+
+	log.Info().Int64("userID", user.ID).Msg("Single sign-on login successful")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// newOIDCModule creates the OIDC module from the stored settings
+func newOIDCModule() (*module.OIDC, error) {
+	settings, err := module.NewSettings(db.NewOptionRepository(db.GetDB())).GetOIDCSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return module.NewOIDC(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+		settings,
+	), nil
+}
+
+// oidcCookieOptions returns the options for the short lived flow cookies.
+// SameSite must be lax since the callback is a cross-site redirect.
+func oidcCookieOptions() *service.CookieOptions {
+	cookieOptions := service.DefaultCookieOptions()
+	cookieOptions.Secure = viper.GetBool("app.tls.status")
+	cookieOptions.MaxAge = int((10 * time.Minute) / time.Second)
+	return cookieOptions
+}
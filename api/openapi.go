@@ -0,0 +1,403 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OpenAPIParameter describes a query parameter of an operation
+type OpenAPIParameter struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// OpenAPIOperation describes a route in the OpenAPI document
+type OpenAPIOperation struct {
+	Method     string
+	Path       string
+	Tag        string
+	Summary    string
+	Public     bool
+	Request    interface{}
+	Parameters []OpenAPIParameter
+}
+
+// paginationParameters are the query parameters of paginated listings
+var paginationParameters = []OpenAPIParameter{
+	{Name: "limit", Type: "integer", Description: "Page size between 1 and 100, defaults to 50"},
+	{Name: "offset", Type: "integer", Description: "Number of items to skip"},
+}
+
+// OpenAPIOperations holds the metadata of every registered API route
+var OpenAPIOperations = []OpenAPIOperation{
+	// Public
+	{Method: http.MethodGet, Path: "/api/v1/public/_health", Tag: "System", Summary: "Health check", Public: true},
+	{Method: http.MethodGet, Path: "/api/v1/public/_ready", Tag: "System", Summary: "Readiness check", Public: true},
+	{Method: http.MethodGet, Path: "/api/v1/public/_metrics", Tag: "System", Summary: "Prometheus metrics, protected by basic auth", Public: true},
+	{Method: http.MethodGet, Path: "/api/v1/openapi.json", Tag: "System", Summary: "OpenAPI document", Public: true},
+	{Method: http.MethodGet, Path: "/api/docs", Tag: "System", Summary: "API reference page", Public: true},
+	{Method: http.MethodPost, Path: "/api/v1/public/action/setup", Tag: "Setup", Summary: "Install the application", Public: true, Request: SetupRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/public/action/setup/status", Tag: "Setup", Summary: "Get the installation status", Public: true},
+	{Method: http.MethodPost, Path: "/api/v1/public/action/login", Tag: "Auth", Summary: "Login with email and password", Public: true, Request: LoginRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/public/action/login/options", Tag: "Auth", Summary: "Get the enabled login methods", Public: true},
+	{Method: http.MethodGet, Path: "/api/v1/public/action/oidc/login", Tag: "Auth", Summary: "Start single sign-on", Public: true},
+	{Method: http.MethodGet, Path: "/api/v1/public/action/oidc/callback", Tag: "Auth", Summary: "Single sign-on callback", Public: true, Parameters: []OpenAPIParameter{
+		{Name: "code", Type: "string", Required: true},
+		{Name: "state", Type: "string", Required: true},
+	}},
+	{Method: http.MethodPost, Path: "/api/v1/public/action/register", Tag: "Auth", Summary: "Register a new account", Public: true, Request: RegisterRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/public/action/verify-email", Tag: "Auth", Summary: "Verify the email of a new account", Public: true, Parameters: []OpenAPIParameter{
+		{Name: "token", Type: "string", Required: true},
+	}},
+	{Method: http.MethodPost, Path: "/api/v1/public/action/logout", Tag: "Auth", Summary: "Logout", Public: true},
+
+	// Profile
+	{Method: http.MethodGet, Path: "/api/v1/action/profile", Tag: "Profile", Summary: "Get the current user"},
+	{Method: http.MethodPut, Path: "/api/v1/action/profile", Tag: "Profile", Summary: "Update the current user"},
+	{Method: http.MethodGet, Path: "/api/v1/action/profile/sessions", Tag: "Profile", Summary: "List the active sessions of the current user"},
+	{Method: http.MethodGet, Path: "/api/v1/users/me/settings", Tag: "Profile", Summary: "Get the preferences of the current user"},
+	{Method: http.MethodPatch, Path: "/api/v1/users/me/settings", Tag: "Profile", Summary: "Set preferences of the current user from a map of string values"},
+	{Method: http.MethodGet, Path: "/api/v1/me/preferences", Tag: "Profile", Summary: "Get the UI preferences of the current user"},
+	{Method: http.MethodPut, Path: "/api/v1/me/preferences", Tag: "Profile", Summary: "Replace the UI preferences of the current user"},
+	{Method: http.MethodPost, Path: "/api/v1/action/batch", Tag: "Batch", Summary: "Run up to 20 API requests in one call"},
+
+	// Settings
+	{Method: http.MethodGet, Path: "/api/v1/action/settings", Tag: "Settings", Summary: "Get the application settings"},
+	{Method: http.MethodPut, Path: "/api/v1/action/settings", Tag: "Settings", Summary: "Update the application settings", Request: SettingsRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/action/settings/oidc", Tag: "Settings", Summary: "Get the single sign-on settings"},
+	{Method: http.MethodPut, Path: "/api/v1/action/settings/oidc", Tag: "Settings", Summary: "Update the single sign-on settings", Request: OIDCSettingsRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/action/settings/registration", Tag: "Settings", Summary: "Get the self-registration settings"},
+	{Method: http.MethodPut, Path: "/api/v1/action/settings/registration", Tag: "Settings", Summary: "Update the self-registration settings", Request: RegistrationSettingsRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/action/settings/cors", Tag: "Settings", Summary: "Get the CORS settings"},
+	{Method: http.MethodPut, Path: "/api/v1/action/settings/cors", Tag: "Settings", Summary: "Update the CORS settings", Request: CORSSettingsRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/action/settings/history", Tag: "Settings", Summary: "List the application settings changes, newest first", Parameters: paginationParameters},
+	{Method: http.MethodGet, Path: "/api/v1/action/options", Tag: "Settings", Summary: "List every stored option with secret values redacted"},
+
+	// Users
+	{Method: http.MethodPost, Path: "/api/v1/users", Tag: "Users", Summary: "Create a user", Request: CreateUserRequest{}},
+	{Method: http.MethodGet, Path: "/api/v1/users", Tag: "Users", Summary: "List users", Parameters: paginationParameters},
+	{Method: http.MethodGet, Path: "/api/v1/users/inactive", Tag: "Users", Summary: "List users that did not login recently", Parameters: append([]OpenAPIParameter{
+		{Name: "days", Type: "integer", Description: "Days without login, defaults to 90"},
+	}, paginationParameters...)},
+	{Method: http.MethodPost, Path: "/api/v1/users/deactivate-inactive", Tag: "Users", Summary: "Deactivate users that did not login recently", Parameters: []OpenAPIParameter{
+		{Name: "days", Type: "integer", Description: "Days without login, defaults to 90"},
+	}},
+	{Method: http.MethodGet, Path: "/api/v1/users/{id}", Tag: "Users", Summary: "Get a user"},
+	{Method: http.MethodPut, Path: "/api/v1/users/{id}", Tag: "Users", Summary: "Update a user", Request: UpdateUserRequest{}},
+	{Method: http.MethodDelete, Path: "/api/v1/users/{id}", Tag: "Users", Summary: "Delete a user"},
+	{Method: http.MethodPost, Path: "/api/v1/users/{id}/activate", Tag: "Users", Summary: "Activate a pending user"},
+	{Method: http.MethodGet, Path: "/api/v1/users/{id}/meta", Tag: "Users", Summary: "List the metadata of a user"},
+	{Method: http.MethodPut, Path: "/api/v1/users/{id}/meta/{key}", Tag: "Users", Summary: "Set a metadata entry of a user", Request: SetUserMetaRequest{}},
+	{Method: http.MethodDelete, Path: "/api/v1/users/{id}/meta/{key}", Tag: "Users", Summary: "Delete a metadata entry of a user"},
+
+	// Backups
+	{Method: http.MethodPost, Path: "/api/v1/action/backups", Tag: "Backups", Summary: "Create a database backup"},
+	{Method: http.MethodGet, Path: "/api/v1/action/backups", Tag: "Backups", Summary: "List backups"},
+	{Method: http.MethodGet, Path: "/api/v1/action/backups/{filename}", Tag: "Backups", Summary: "Download a backup"},
+	{Method: http.MethodGet, Path: "/api/v1/action/scheduler/jobs", Tag: "Scheduler", Summary: "List scheduled jobs"},
+	{Method: http.MethodPost, Path: "/api/v1/action/reload", Tag: "System", Summary: "Reload the configs that can change without a restart"},
+	{Method: http.MethodGet, Path: "/api/v1/action/stats", Tag: "Stats", Summary: "Summarize activity for a period"},
+	{Method: http.MethodGet, Path: "/api/v1/action/stats/db", Tag: "Stats", Summary: "Get the database connection pool stats"},
+	{Method: http.MethodGet, Path: "/api/v1/action/summary", Tag: "Stats", Summary: "Get the admin dashboard summary"},
+	{Method: http.MethodGet, Path: "/api/v1/action/activities/export", Tag: "Activities", Summary: "Export activities as CSV or NDJSON"},
+	{Method: http.MethodPost, Path: "/api/v1/action/reset", Tag: "Setup", Summary: "Delete all data so setup can run again"},
+}
+
+// pathParameterPattern matches path parameters like {id}
+var pathParameterPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// BuildOpenAPISpec builds the OpenAPI 3 document from the operations
+func BuildOpenAPISpec(operations []OpenAPIOperation) map[string]interface{} {
+	paths := map[string]interface{}{}
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"errorCode":    map[string]interface{}{"type": "string"},
+				"errorMessage": map[string]interface{}{"type": "string"},
+				"requestId":    map[string]interface{}{"type": "string"},
+				"details":      map[string]interface{}{"type": "object"},
+			},
+		},
+	}
+
+	for _, operation := range operations {
+		item, ok := paths[operation.Path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[operation.Path] = item
+		}
+
+		spec := map[string]interface{}{
+			"tags":    []string{operation.Tag},
+			"summary": operation.Summary,
+			"responses": map[string]interface{}{
+				"default": map[string]interface{}{
+					"description": "Error response",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+						},
+					},
+				},
+			},
+		}
+
+		if operation.Public {
+			spec["security"] = []interface{}{}
+		}
+
+		parameters := []interface{}{}
+		for _, match := range pathParameterPattern.FindAllStringSubmatch(operation.Path, -1) {
+			parameterType := "string"
+			if match[1] == "id" {
+				parameterType = "integer"
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": parameterType},
+			})
+		}
+		for _, parameter := range operation.Parameters {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        parameter.Name,
+				"in":          "query",
+				"required":    parameter.Required,
+				"description": parameter.Description,
+				"schema":      map[string]interface{}{"type": parameter.Type},
+			})
+		}
+		if len(parameters) > 0 {
+			spec["parameters"] = parameters
+		}
+
+		if operation.Request != nil {
+			requestType := reflect.TypeOf(operation.Request)
+			schemas[requestType.Name()] = structSchema(requestType)
+			spec["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"$ref": "#/components/schemas/" + requestType.Name()},
+					},
+				},
+			}
+		}
+
+		item[strings.ToLower(operation.Method)] = spec
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Tut API",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "header",
+					"name": "X-API-Key",
+				},
+				"session": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "cookie",
+					"name": "_tut_session",
+				},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"session": []string{}},
+		},
+	}
+}
+
+// structSchema builds a JSON schema from the json, label and validate tags of a struct
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := typeSchema(field.Type)
+		if label := field.Tag.Get("label"); label != "" {
+			schema["title"] = label
+		}
+
+		// Rules after dive apply to the slice items
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		for i, rule := range rules {
+			if rule == "dive" {
+				if items, ok := schema["items"].(map[string]interface{}); ok {
+					applyRules(items, rules[i+1:])
+				}
+				rules = rules[:i]
+				break
+			}
+		}
+
+		if applyRules(schema, rules) {
+			required = append(required, name)
+		}
+
+		properties[name] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// typeSchema returns the JSON schema type of a Go type
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// applyRules maps validation rules to schema constraints and reports whether the field is required
+func applyRules(schema map[string]interface{}, rules []string) bool {
+	required := false
+	minKey, maxKey := "minLength", "maxLength"
+	if schema["type"] == "array" {
+		minKey, maxKey = "minItems", "maxItems"
+	} else if schema["type"] == "integer" || schema["type"] == "number" {
+		minKey, maxKey = "minimum", "maximum"
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+		case "required_if":
+			schema["description"] = "Required when " + strings.Replace(param, " ", " is ", 1)
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "uuid":
+			schema["format"] = "uuid"
+		case "min", "gte":
+			if value, err := strconv.Atoi(param); err == nil {
+				schema[minKey] = value
+			}
+		case "max", "lte":
+			if value, err := strconv.Atoi(param); err == nil {
+				schema[maxKey] = value
+			}
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "strong_password":
+			schema["description"] = "At least one uppercase, one lowercase, one digit, and one special character"
+		}
+	}
+
+	return required
+}
+
+// OpenAPIAction serves the OpenAPI document
+func OpenAPIAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("OpenAPI endpoint called")
+
+	service.WriteJSON(w, http.StatusOK, BuildOpenAPISpec(OpenAPIOperations))
+}
+
+var (
+	// apiDocsScript renders the OpenAPI document in the browser
+	//go:embed docs/docs.js
+	apiDocsScript string
+
+	// apiDocsStyle styles the API reference page
+	//go:embed docs/docs.css
+	apiDocsStyle string
+)
+
+// apiDocsPage inlines the embedded script and style so the page loads nothing
+// from outside of the server
+var apiDocsPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Tut API Reference</title>
+	<meta charset="utf-8"/>
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<style>` + apiDocsStyle + `</style>
+</head>
+<body>
+	<div id="docs">Loading...</div>
+	<script>` + apiDocsScript + `</script>
+</body>
+</html>
+`
+
+// apiDocsPolicy only allows the inline script and style by hash and the
+// OpenAPI document fetch to the same origin
+var apiDocsPolicy = fmt.Sprintf(
+	"default-src 'none'; script-src '%s'; style-src '%s'; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'",
+	cspHash(apiDocsScript),
+	cspHash(apiDocsStyle),
+)
+
+// cspHash returns the Content-Security-Policy source for an inline element
+func cspHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// APIDocsAction serves the API reference page
+func APIDocsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("API docs endpoint called")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", apiDocsPolicy)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(apiDocsPage))
+}
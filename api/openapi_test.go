@@ -0,0 +1,78 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnitOpenAPIAction tests the generated OpenAPI document
+func TestUnitOpenAPIAction(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+
+	OpenAPIAction(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	paths := spec["paths"].(map[string]interface{})
+	user := paths["/api/v1/users/{id}"].(map[string]interface{})
+	assert.Contains(t, user, "get")
+	assert.Contains(t, user, "put")
+	assert.Contains(t, user, "delete")
+
+	parameters := user["get"].(map[string]interface{})["parameters"].([]interface{})
+	assert.Equal(t, "id", parameters[0].(map[string]interface{})["name"])
+	assert.Equal(t, "path", parameters[0].(map[string]interface{})["in"])
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	createUser := schemas["CreateUserRequest"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"email", "password", "role"}, createUser["required"])
+
+	properties := createUser["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":      "string",
+		"title":     "Email",
+		"format":    "email",
+		"minLength": float64(4),
+		"maxLength": float64(60),
+	}, properties["email"])
+	assert.Equal(t, []interface{}{"admin", "user", "readonly"}, properties["role"].(map[string]interface{})["enum"])
+	assert.Equal(t, "boolean", properties["isActive"].(map[string]interface{})["type"])
+
+	cors := schemas["CORSSettingsRequest"].(map[string]interface{})["properties"].(map[string]interface{})
+	origins := cors["allowedOrigins"].(map[string]interface{})
+	assert.Equal(t, float64(100), origins["maxItems"])
+	assert.Equal(t, float64(255), origins["items"].(map[string]interface{})["maxLength"])
+}
+
+// TestUnitAPIDocsAction tests the API reference page loads nothing from outside of the server
+func TestUnitAPIDocsAction(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	w := httptest.NewRecorder()
+
+	APIDocsAction(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "<script src=")
+	assert.NotContains(t, w.Body.String(), "https://")
+	assert.Contains(t, w.Body.String(), "<script>"+apiDocsScript+"</script>")
+
+	policy := w.Header().Get("Content-Security-Policy")
+	assert.Contains(t, policy, "default-src 'none'")
+	assert.Contains(t, policy, "script-src '"+cspHash(apiDocsScript)+"'")
+	assert.Contains(t, policy, "style-src '"+cspHash(apiDocsStyle)+"'")
+	assert.Contains(t, policy, "connect-src 'self'")
+}
@@ -5,10 +5,13 @@
 package api
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"time"
 
+	"github.com/clivern/tut/db"
 	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/module"
 	"github.com/clivern/tut/service"
 
 	"github.com/rs/zerolog/log"
@@ -20,9 +23,7 @@ func GetProfileAction(w http.ResponseWriter, r *http.Request) {
 
 	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
-		service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-			"errorMessage": "Not authenticated",
-		})
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
 		return
 	}
 
@@ -39,6 +40,48 @@ func GetProfileAction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListProfileSessionsAction lists the active sessions of the current user
+func ListProfileSessionsAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("List profile sessions endpoint called")
+
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+		return
+	}
+
+	sessionManager := module.NewSessionManager(
+		db.NewSessionRepository(db.GetDB()),
+		db.NewUserRepository(db.GetDB()),
+	)
+
+	sessions, err := sessionManager.GetActiveSessions(user.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list sessions")
+		service.WriteInternalError(w, "Failed to list sessions")
+		return
+	}
+
+	// Requests authenticated with an API key have no current session
+	currentToken := service.GetCookie(r, "_tut_session")
+
+	sessionList := make([]map[string]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		sessionList = append(sessionList, map[string]interface{}{
+			"id":        session.ID,
+			"ipAddress": session.IPAddress,
+			"userAgent": session.UserAgent,
+			"createdAt": session.CreatedAt.UTC().Format(time.RFC3339),
+			"expiresAt": session.ExpiresAt.UTC().Format(time.RFC3339),
+			"current":   currentToken != "" && subtle.ConstantTimeCompare([]byte(session.Token), []byte(currentToken)) == 1,
+		})
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": sessionList,
+	})
+}
+
 // UpdateProfileAction handles user profile update requests
 func UpdateProfileAction(w http.ResponseWriter, _ *http.Request) {
 	log.Debug().Msg("Update profile endpoint called")
@@ -0,0 +1,178 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RegisterRequest represents the registration request payload
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,min=4,max=60" label:"Email"`
+	Password string `json:"password" validate:"required,strong_password,min=8,max=60" label:"Password"`
+}
+
+// RegisterAction handles user self-registration requests
+func RegisterAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Register endpoint called")
+
+	var req RegisterRequest
+	if err := service.DecodeAndValidate(r, &req); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	registrationSettings, err := settingsModule.GetRegistrationSettings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get registration settings")
+		service.WriteInternalError(w, "Failed to register")
+		return
+	}
+
+	// Hashing happens before the transaction so the write lock is not held
+	// for the bcrypt cost, and existing emails take as long as new ones
+	hashedPassword, err := service.HashPassword(req.Password)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to hash password")
+		service.WriteInternalError(w, "Failed to register")
+		return
+	}
+
+	// The user and its verification token are created together
+	var user *db.User
+	var token string
+	err = db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		var err error
+		user, token, err = module.NewRegistration(tx.Users, tx.UsersMeta, registrationSettings).Register(req.Email, hashedPassword)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, module.ErrRegistrationDisabled) {
+			service.WriteError(w, http.StatusForbidden, service.ErrorCodeRegistrationDisabled, "Registration is disabled")
+			return
+		}
+		if errors.Is(err, module.ErrRegistrationEmailNotAllowed) {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Email domain is not allowed")
+			return
+		}
+		if errors.Is(err, module.ErrUserEmailAlreadyExists) {
+			// The response matches a new registration so it does not reveal
+			// which emails have an account, the owner is told by email instead
+			if err := sendAccountExistsEmail(settingsModule, req.Email); err != nil {
+				log.Error().Err(err).Msg("Failed to send account exists email")
+			}
+			log.Info().Msg("Registration attempted for an existing email")
+			writeRegistered(w)
+			return
+		}
+		log.Error().Err(err).Msg("Failed to register user")
+		service.WriteInternalError(w, "Failed to register")
+		return
+	}
+
+	// The account stays pending if the email fails, an admin can still activate it
+	if err := sendVerificationEmail(settingsModule, user.Email, token); err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to send verification email")
+	}
+
+	log.Info().Int64("userID", user.ID).Msg("User registered successfully")
+	writeRegistered(w)
+}
+
+// writeRegistered writes the response shared by new and existing emails
+func writeRegistered(w http.ResponseWriter) {
+	service.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"successMessage": "Registration successful, please check your email to verify your account",
+	})
+}
+
+// VerifyEmailAction handles email verification link requests
+func VerifyEmailAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Verify email endpoint called")
+
+	registrationModule := module.NewRegistration(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+		&module.RegistrationSettings{},
+	)
+
+	user, err := registrationModule.VerifyEmail(r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, module.ErrVerificationTokenInvalid) {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Verification link is invalid or expired")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to verify email")
+		service.WriteInternalError(w, "Failed to verify email")
+		return
+	}
+
+	log.Info().Int64("userID", user.ID).Msg("User email verified successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Email verified successfully, you can now login",
+	})
+}
+
+// sendVerificationEmail emails the verification link using the SMTP settings
+func sendVerificationEmail(settingsModule *module.Settings, email, token string) error {
+	settings, err := settingsModule.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf(
+		"%s/api/v1/public/action/verify-email?token=%s",
+		strings.TrimRight(settings.ApplicationURL, "/"),
+		url.QueryEscape(token),
+	)
+
+	body := fmt.Sprintf(
+		"Welcome to %s!\n\nPlease verify your email address by opening the link below:\n\n%s\n\nThe link expires in 24 hours.\n",
+		settings.ApplicationName,
+		link,
+	)
+
+	return sendRegistrationEmail(settings, email, fmt.Sprintf("Verify your %s account", settings.ApplicationName), body)
+}
+
+// sendAccountExistsEmail tells the owner of an email that someone tried to
+// register it again
+func sendAccountExistsEmail(settingsModule *module.Settings, email string) error {
+	settings, err := settingsModule.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Someone tried to create a %s account with this email address, but an account already exists.\n\nYou can login at %s. If this was not you, you can ignore this email.\n",
+		settings.ApplicationName,
+		strings.TrimRight(settings.ApplicationURL, "/"),
+	)
+
+	return sendRegistrationEmail(settings, email, fmt.Sprintf("Your %s account", settings.ApplicationName), body)
+}
+
+// sendRegistrationEmail sends an email using the SMTP settings
+func sendRegistrationEmail(settings *module.SettingsOptions, email, subject, body string) error {
+	return service.SendMail(&service.SMTPConfig{
+		Server:    settings.SMTPServer,
+		Port:      settings.SMTPPort,
+		FromEmail: settings.SMTPFromEmail,
+		Username:  settings.SMTPUsername,
+		Password:  settings.SMTPPassword,
+		UseTLS:    settings.SMTPUseTLS,
+	}, email, subject, body)
+}
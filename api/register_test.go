@@ -0,0 +1,89 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestIntegrationRegisterAction tests registration does not reveal existing accounts
+func TestIntegrationRegisterAction(t *testing.T) {
+	service.SetBcryptCost(bcrypt.MinCost)
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_register.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	for _, query := range []string{
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL DEFAULT 'user',
+			api_key VARCHAR(255) UNIQUE,
+			is_active BOOLEAN DEFAULT 1,
+			last_login_at DATETIME NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE users_meta (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL,
+			value TEXT,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, key)
+		)`,
+		`CREATE TABLE options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL UNIQUE,
+			value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	} {
+		_, err := db.GetDB().Exec(query)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.NewOptionRepository(db.GetDB()).Create("registration_enabled", "1"))
+
+	register := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/api/v1/public/action/register",
+			strings.NewReader(`{"email":"user@example.com","password":"Password123!"}`),
+		)
+		w := httptest.NewRecorder()
+		RegisterAction(w, req)
+		return w
+	}
+
+	created := register()
+	assert.Equal(t, http.StatusCreated, created.Code)
+
+	existing := register()
+	assert.Equal(t, http.StatusCreated, existing.Code)
+	assert.Equal(t, created.Body.String(), existing.Body.String())
+
+	count, err := db.NewUserRepository(db.GetDB()).Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReloadConfigAction returns the handler that reloads the configuration file
+// with reload, the same reload a SIGHUP triggers
+func ReloadConfigAction(reload module.ConfigReloadFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Debug().Msg("Reload config endpoint called")
+
+		ipAddress := service.ClientIP(r)
+		userAgent := r.UserAgent()
+		activity := &db.Activity{IPAddress: &ipAddress, UserAgent: &userAgent}
+
+		if user, ok := middleware.GetUserFromContext(r.Context()); ok {
+			activity.UserID = &user.ID
+			activity.UserEmail = &user.Email
+		}
+
+		changed, err := reload(activity)
+
+		if errors.Is(err, module.ErrConfigInvalid) {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, module.ErrConfigImmutable) {
+			service.WriteError(w, http.StatusConflict, service.ErrorCodeConflict, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to reload configuration")
+			service.WriteInternalError(w, "Failed to reload configuration")
+			return
+		}
+
+		service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"successMessage": "Configuration reloaded successfully",
+			"changed":        changed,
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ListSchedulerJobsAction handles scheduled job listing requests
+func ListSchedulerJobsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("List scheduler jobs endpoint called")
+
+	jobs := module.GetScheduler().Jobs()
+
+	jobList := make([]map[string]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		item := map[string]interface{}{
+			"name":           job.Name,
+			"interval":       job.Interval.String(),
+			"runs":           job.Runs,
+			"lastRunAt":      nil,
+			"lastDurationMs": job.LastDuration.Milliseconds(),
+			"lastError":      job.LastError,
+			"nextRunAt":      nil,
+		}
+		if job.LastRunAt != nil {
+			item["lastRunAt"] = job.LastRunAt.Format(time.RFC3339)
+		}
+		if job.NextRunAt != nil {
+			item["nextRunAt"] = job.NextRunAt.Format(time.RFC3339)
+		}
+		jobList = append(jobList, item)
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"jobs": jobList,
+	})
+}
@@ -5,15 +5,35 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
 	"github.com/clivern/tut/module"
 	"github.com/clivern/tut/service"
 
 	"github.com/rs/zerolog/log"
 )
 
+// Activity actions recorded for settings changes
+const (
+	// SettingsUpdateAction is recorded for application settings changes
+	SettingsUpdateAction = "settings.update"
+	// OIDCSettingsUpdateAction is recorded for single sign-on settings changes
+	OIDCSettingsUpdateAction = "settings.oidc.update"
+	// RegistrationSettingsUpdateAction is recorded for self-registration settings changes
+	RegistrationSettingsUpdateAction = "settings.registration.update"
+	// CORSSettingsUpdateAction is recorded for cross-origin settings changes
+	CORSSettingsUpdateAction = "settings.cors.update"
+)
+
+// redactedOptionValue replaces the value of a secret option in responses
+const redactedOptionValue = "********"
+
 // SettingsRequest represents the settings request payload
 type SettingsRequest struct {
 	ApplicationURL   string `json:"applicationURL" validate:"required,url,min=4,max=60" label:"Application URL"`
@@ -38,8 +58,7 @@ func UpdateSettingsAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
-	err := settingsModule.UpdateSettings(&module.SettingsOptions{
+	options := &module.SettingsOptions{
 		ApplicationURL:   req.ApplicationURL,
 		ApplicationEmail: req.ApplicationEmail,
 		ApplicationName:  req.ApplicationName,
@@ -50,12 +69,25 @@ func UpdateSettingsAction(w http.ResponseWriter, r *http.Request) {
 		SMTPUsername:     req.SMTPUsername,
 		SMTPPassword:     req.SMTPPassword,
 		SMTPUseTLS:       req.SMTPUseTLS,
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		settingsModule := module.NewSettings(tx.Options)
+
+		previous, err := settingsModule.GetSettings()
+		if err != nil {
+			return err
+		}
+
+		if err := settingsModule.UpdateSettings(options); err != nil {
+			return err
+		}
+
+		return recordSettingsChange(tx, r, SettingsUpdateAction, module.DiffSettings(previous, options))
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to update settings")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to update settings",
-		})
+		service.WriteInternalError(w, "Failed to update settings")
 		return
 	}
 
@@ -65,6 +97,34 @@ func UpdateSettingsAction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// recordSettingsChange saves the audit record of a settings change. It runs in
+// the transaction of the change so a change is only saved along with its record.
+func recordSettingsChange(tx *db.Repos, r *http.Request, action string, changes map[string]interface{}) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	details, err := json.Marshal(map[string]interface{}{"changes": changes})
+	if err != nil {
+		return err
+	}
+	detailsStr := string(details)
+
+	currentUser, _ := middleware.GetUserFromContext(r.Context())
+	ipAddress := service.ClientIP(r)
+	userAgent := r.UserAgent()
+
+	return tx.Activities.Create(&db.Activity{
+		UserID:     &currentUser.ID,
+		UserEmail:  &currentUser.Email,
+		Action:     action,
+		EntityType: "settings",
+		Details:    &detailsStr,
+		IPAddress:  &ipAddress,
+		UserAgent:  &userAgent,
+	})
+}
+
 // GetSettingsAction handles user settings get requests
 func GetSettingsAction(w http.ResponseWriter, _ *http.Request) {
 	log.Debug().Msg("Get settings endpoint called")
@@ -73,12 +133,345 @@ func GetSettingsAction(w http.ResponseWriter, _ *http.Request) {
 	settings, err := settingsModule.GetSettings()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get settings")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to get settings",
-		})
+		service.WriteInternalError(w, "Failed to get settings")
 		return
 	}
 	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
 		"settings": settings,
 	})
 }
+
+// ListSettingsHistoryAction lists the application settings changes, newest first
+func ListSettingsHistoryAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("List settings history endpoint called")
+
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	limit := 50
+	offset := 0
+
+	if limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	if offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	activities, err := db.NewActivityRepository(db.GetDB()).ListByAction(SettingsUpdateAction, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list settings history")
+		service.WriteInternalError(w, "Failed to list settings history")
+		return
+	}
+
+	history := make([]map[string]interface{}, 0, len(activities))
+	for _, activity := range activities {
+		var details struct {
+			Changes map[string]interface{} `json:"changes"`
+		}
+		if activity.Details != nil {
+			if err := json.Unmarshal([]byte(*activity.Details), &details); err != nil {
+				log.Error().Err(err).Int64("activityID", activity.ID).Msg("Failed to decode settings change")
+			}
+		}
+
+		history = append(history, map[string]interface{}{
+			"id":        activity.ID,
+			"userID":    activity.UserID,
+			"userEmail": activity.UserEmail,
+			"changes":   details.Changes,
+			"ipAddress": activity.IPAddress,
+			"createdAt": activity.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"history": history,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
+
+// ListOptionsAction lists every stored option with secret values left out
+func ListOptionsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("List options endpoint called")
+
+	options, err := db.NewOptionRepository(db.GetDB()).List()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list options")
+		service.WriteInternalError(w, "Failed to list options")
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(options))
+	for _, option := range options {
+		value := option.Value
+		if module.IsSecretOption(option.Key) && value != "" {
+			value = redactedOptionValue
+		}
+
+		result = append(result, map[string]interface{}{
+			"key":       option.Key,
+			"value":     value,
+			"updatedAt": option.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"options": result,
+	})
+}
+
+// OIDCSettingsRequest represents the single sign-on settings request payload
+type OIDCSettingsRequest struct {
+	Enabled           bool   `json:"enabled" label:"Enabled"`
+	IssuerURL         string `json:"issuerURL" validate:"required_if=Enabled true,omitempty,url,max=255" label:"Issuer URL"`
+	ClientID          string `json:"clientID" validate:"required_if=Enabled true,max=255" label:"Client ID"`
+	ClientSecret      string `json:"clientSecret" validate:"required_if=Enabled true,max=255" label:"Client Secret"`
+	RedirectURL       string `json:"redirectURL" validate:"required_if=Enabled true,omitempty,url,max=255" label:"Redirect URL"`
+	DefaultRole       string `json:"defaultRole" validate:"required,oneof=admin user readonly" label:"Default Role"`
+	LocalLoginEnabled bool   `json:"localLoginEnabled" label:"Local Login Enabled"`
+}
+
+// UpdateOIDCSettingsAction handles single sign-on settings update requests
+func UpdateOIDCSettingsAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Update OIDC settings endpoint called")
+
+	var req OIDCSettingsRequest
+	if err := service.DecodeAndValidate(r, &req); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	// Never lock everyone out of the application
+	if !req.Enabled && !req.LocalLoginEnabled {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Password login can only be disabled when single sign-on is enabled")
+		return
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		settingsModule := module.NewSettings(tx.Options)
+
+		previous, err := settingsModule.GetOIDCSettings()
+		if err != nil {
+			return err
+		}
+
+		// The settings form sends back the masked secret when it is unchanged
+		clientSecret := req.ClientSecret
+		if clientSecret == redactedOptionValue {
+			clientSecret = previous.ClientSecret
+		}
+
+		options := &module.OIDCSettings{
+			Enabled:           req.Enabled,
+			IssuerURL:         req.IssuerURL,
+			ClientID:          req.ClientID,
+			ClientSecret:      clientSecret,
+			RedirectURL:       req.RedirectURL,
+			DefaultRole:       req.DefaultRole,
+			LocalLoginEnabled: req.LocalLoginEnabled,
+		}
+		if err := settingsModule.UpdateOIDCSettings(options); err != nil {
+			return err
+		}
+
+		return recordSettingsChange(tx, r, OIDCSettingsUpdateAction, module.DiffOIDCSettings(previous, options))
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update single sign-on settings")
+		service.WriteInternalError(w, "Failed to update single sign-on settings")
+		return
+	}
+
+	log.Info().Msg("Single sign-on settings updated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Single sign-on settings updated successfully",
+	})
+}
+
+// GetOIDCSettingsAction handles single sign-on settings get requests
+func GetOIDCSettingsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Get OIDC settings endpoint called")
+
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	settings, err := settingsModule.GetOIDCSettings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get single sign-on settings")
+		service.WriteInternalError(w, "Failed to get single sign-on settings")
+		return
+	}
+
+	clientSecret := settings.ClientSecret
+	if clientSecret != "" {
+		clientSecret = redactedOptionValue
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"enabled":           settings.Enabled,
+			"issuerURL":         settings.IssuerURL,
+			"clientID":          settings.ClientID,
+			"clientSecret":      clientSecret,
+			"redirectURL":       settings.RedirectURL,
+			"defaultRole":       settings.DefaultRole,
+			"localLoginEnabled": settings.LocalLoginEnabled,
+		},
+	})
+}
+
+// RegistrationSettingsRequest represents the self-registration settings request payload
+type RegistrationSettingsRequest struct {
+	Enabled        bool     `json:"enabled" label:"Enabled"`
+	BlockedDomains []string `json:"blockedDomains" validate:"max=500,dive,min=1,max=255" label:"Blocked Domains"`
+}
+
+// UpdateRegistrationSettingsAction handles self-registration settings update requests
+func UpdateRegistrationSettingsAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Update registration settings endpoint called")
+
+	var req RegistrationSettingsRequest
+	if err := service.DecodeAndValidate(r, &req); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		settingsModule := module.NewSettings(tx.Options)
+
+		previous, err := settingsModule.GetRegistrationSettings()
+		if err != nil {
+			return err
+		}
+
+		err = settingsModule.UpdateRegistrationSettings(&module.RegistrationSettings{
+			Enabled:        req.Enabled,
+			BlockedDomains: req.BlockedDomains,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The stored domains are normalized so the diff reads them back
+		current, err := settingsModule.GetRegistrationSettings()
+		if err != nil {
+			return err
+		}
+
+		return recordSettingsChange(tx, r, RegistrationSettingsUpdateAction, module.DiffRegistrationSettings(previous, current))
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update registration settings")
+		service.WriteInternalError(w, "Failed to update registration settings")
+		return
+	}
+
+	log.Info().Msg("Registration settings updated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Registration settings updated successfully",
+	})
+}
+
+// GetRegistrationSettingsAction handles self-registration settings get requests
+func GetRegistrationSettingsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Get registration settings endpoint called")
+
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	settings, err := settingsModule.GetRegistrationSettings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get registration settings")
+		service.WriteInternalError(w, "Failed to get registration settings")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"enabled":        settings.Enabled,
+			"blockedDomains": settings.BlockedDomains,
+		},
+	})
+}
+
+// CORSSettingsRequest represents the cross-origin settings request payload
+type CORSSettingsRequest struct {
+	AllowedOrigins []string `json:"allowedOrigins" validate:"max=100,dive,required,max=255" label:"Allowed Origins"`
+}
+
+// UpdateCORSSettingsAction handles cross-origin settings update requests
+func UpdateCORSSettingsAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Update CORS settings endpoint called")
+
+	var req CORSSettingsRequest
+	if err := service.DecodeAndValidate(r, &req); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	for _, origin := range req.AllowedOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Allowed origins must start with http:// or https://")
+			return
+		}
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		settingsModule := module.NewSettings(tx.Options)
+
+		previous, err := settingsModule.GetCORSSettings()
+		if err != nil {
+			return err
+		}
+
+		err = settingsModule.UpdateCORSSettings(&module.CORSSettings{
+			AllowedOrigins: req.AllowedOrigins,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The stored origins are normalized so the diff reads them back
+		current, err := settingsModule.GetCORSSettings()
+		if err != nil {
+			return err
+		}
+
+		return recordSettingsChange(tx, r, CORSSettingsUpdateAction, module.DiffCORSSettings(previous, current))
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update CORS settings")
+		service.WriteInternalError(w, "Failed to update CORS settings")
+		return
+	}
+
+	log.Info().Msg("CORS settings updated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "CORS settings updated successfully",
+	})
+}
+
+// GetCORSSettingsAction handles cross-origin settings get requests
+func GetCORSSettingsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Get CORS settings endpoint called")
+
+	settingsModule := module.NewSettings(db.NewOptionRepository(db.GetDB()))
+	settings, err := settingsModule.GetCORSSettings()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get CORS settings")
+		service.WriteInternalError(w, "Failed to get CORS settings")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"settings": map[string]interface{}{
+			"allowedOrigins": settings.AllowedOrigins,
+		},
+	})
+}
@@ -8,10 +8,12 @@ import (
 	"net/http"
 
 	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
 	"github.com/clivern/tut/module"
 	"github.com/clivern/tut/service"
 
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
 )
 
 // SetupRequest represents the setup request payload
@@ -37,26 +39,31 @@ func SetupAction(w http.ResponseWriter, r *http.Request) {
 		db.NewUserRepository(db.GetDB()),
 	)
 
-	if setupModule.IsInstalled() {
-		service.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": "Application is already installed",
-		})
+	installed, err := setupModule.IsInstalled()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check the installation state")
+		service.WriteInternalError(w, "Failed to complete setup")
+		return
+	}
+	if installed {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeAlreadyInstalled, "Application is already installed")
 		return
 	}
 
-	err := setupModule.Install(&module.SetupOptions{
-		ApplicationURL:   req.ApplicationURL,
-		ApplicationEmail: req.ApplicationEmail,
-		ApplicationName:  req.ApplicationName,
-		AdminEmail:       req.AdminEmail,
-		AdminPassword:    req.AdminPassword,
+	// Install in a transaction so a failed setup can be retried
+	err = db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		return module.NewSetup(tx.Options, tx.Users).Install(&module.SetupOptions{
+			ApplicationURL:   req.ApplicationURL,
+			ApplicationEmail: req.ApplicationEmail,
+			ApplicationName:  req.ApplicationName,
+			AdminEmail:       req.AdminEmail,
+			AdminPassword:    req.AdminPassword,
+		})
 	})
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to complete setup")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to complete setup",
-		})
+		service.WriteInternalError(w, "Failed to complete setup")
 		return
 	}
 
@@ -72,7 +79,45 @@ func SetupStatusAction(w http.ResponseWriter, _ *http.Request) {
 		db.NewOptionRepository(db.GetDB()),
 		db.NewUserRepository(db.GetDB()),
 	)
+	installed, err := setupModule.IsInstalled()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check the installation state")
+		service.WriteInternalError(w, "Failed to check setup status")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"installed": installed,
+	})
+}
+
+// ResetAction deletes all application data so setup can run again. It is
+// meant for development and test environments and is disabled unless
+// app.admin.allow_reset is on.
+func ResetAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Reset endpoint called")
+
+	if !viper.GetBool("app.admin.allow_reset") {
+		service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "Reset is disabled")
+		return
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		return module.NewSetupFromRepos(tx).Reset()
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reset application")
+		service.WriteInternalError(w, "Failed to reset application")
+		return
+	}
+
+	// The session of the current user was deleted with the rest of the data
+	service.DeleteCookie(w, "_tut_session")
+	middleware.ForgetInstalled()
+
+	log.Warn().Msg("Application data was reset")
 	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"installed": setupModule.IsInstalled(),
+		"successMessage": "Application reset successfully",
 	})
 }
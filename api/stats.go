@@ -0,0 +1,97 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// statsDateLayout is the layout of the from and to query parameters
+const statsDateLayout = "2006-01-02"
+
+// GetStatsAction handles activity summary requests
+func GetStatsAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Get stats endpoint called")
+
+	from, to, ok := statsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	// The to date is inclusive so the period ends at the start of the next day
+	summary, err := db.NewActivityRepository(db.GetDB()).Summarize(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to summarize activities")
+		service.WriteInternalError(w, "Failed to get stats")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"from":              from.Format(statsDateLayout),
+		"to":                to.Format(statsDateLayout),
+		"totalUploads":      summary.TotalUploads,
+		"totalDownloads":    summary.TotalDownloads,
+		"totalDeletes":      summary.TotalDeletes,
+		"newUsers":          summary.NewUsers,
+		"uniqueActiveUsers": summary.UniqueActiveUsers,
+	})
+}
+
+// GetDatabaseStatsAction handles database connection pool stats requests
+func GetDatabaseStatsAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Get database stats endpoint called")
+
+	stats := db.GetDB().Stats()
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"maxOpenConnections": stats.MaxOpenConnections,
+		"openConnections":    stats.OpenConnections,
+		"inUse":              stats.InUse,
+		"idle":               stats.Idle,
+		"waitCount":          stats.WaitCount,
+		"waitDurationMs":     stats.WaitDuration.Milliseconds(),
+		"maxIdleClosed":      stats.MaxIdleClosed,
+		"maxIdleTimeClosed":  stats.MaxIdleTimeClosed,
+		"maxLifetimeClosed":  stats.MaxLifetimeClosed,
+	})
+}
+
+// statsPeriod parses the from and to query parameters, defaulting to the last 30 days
+func statsPeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -30)
+
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse(statsDateLayout, value)
+		if err != nil {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "From must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse(statsDateLayout, value)
+		if err != nil {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "To must be a date in YYYY-MM-DD format")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	if from.After(to) {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "From must not be after to")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
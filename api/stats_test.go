@@ -0,0 +1,42 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationDatabaseStats tests the database connection pool stats endpoint
+func TestIntegrationDatabaseStats(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_database_stats.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile, MaxOpenConns: 3}))
+	defer db.CloseDB()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/action/stats/db", nil)
+	w := httptest.NewRecorder()
+
+	GetDatabaseStatsAction(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]int64
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(3), body["maxOpenConnections"])
+	assert.Contains(t, body, "inUse")
+	assert.Contains(t, body, "idle")
+	assert.Contains(t, body, "waitCount")
+}
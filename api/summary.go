@@ -0,0 +1,49 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// GetSummaryAction handles admin dashboard summary requests
+func GetSummaryAction(w http.ResponseWriter, _ *http.Request) {
+	log.Debug().Msg("Get summary endpoint called")
+
+	dashboard := module.NewDashboard(
+		db.NewUserRepository(db.GetDB()),
+		db.NewActivityRepository(db.GetDB()),
+	)
+
+	ttl := time.Duration(viper.GetInt("app.admin.summary_cache_seconds")) * time.Second
+
+	summary, err := dashboard.GetSummary(ttl)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get dashboard summary")
+		service.WriteInternalError(w, "Failed to get summary")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"users": map[string]interface{}{
+			"total":    summary.TotalUsers,
+			"active":   summary.ActiveUsers,
+			"inactive": summary.InactiveUsers,
+		},
+		"uploads": map[string]interface{}{
+			"last24h": summary.UploadsLast24h,
+			"last7d":  summary.UploadsLast7d,
+		},
+		"computedAt": summary.ComputedAt.Format(time.RFC3339),
+	})
+}
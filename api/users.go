@@ -6,8 +6,12 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/clivern/tut/db"
@@ -18,6 +22,28 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// User preference limits
+const (
+	// MaxUserPreferences is the most preferences a single request can set
+	MaxUserPreferences = 50
+	// MaxUserPreferenceBytes is the largest preference value
+	MaxUserPreferenceBytes = 1000
+	// MaxUIPreferenceBytes is the largest UI preference value
+	MaxUIPreferenceBytes = 100
+)
+
+var (
+	// preferenceKeyPattern matches the allowed preference keys
+	preferenceKeyPattern = regexp.MustCompile(`^[a-z_]{1,50}$`)
+	// userMetaKeyPattern matches the user metadata keys admins can set
+	userMetaKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+)
+
+// SetUserMetaRequest represents the set user metadata request payload
+type SetUserMetaRequest struct {
+	Value string `json:"value" validate:"max=4096" label:"Value"`
+}
+
 // CreateUserRequest represents the create user request payload
 type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email,min=4,max=60" label:"Email"`
@@ -44,7 +70,10 @@ func CreateUserAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userModule := module.NewUser(db.NewUserRepository(db.GetDB()))
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
 	user, err := userModule.CreateUser(&module.CreateUserOptions{
 		Email:    req.Email,
 		Password: req.Password,
@@ -54,15 +83,11 @@ func CreateUserAction(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		if errors.Is(err, module.ErrUserEmailAlreadyExists) {
-			service.WriteJSON(w, http.StatusConflict, map[string]interface{}{
-				"errorMessage": "User with this email already exists",
-			})
+			service.WriteError(w, http.StatusConflict, service.ErrorCodeConflict, "User with this email already exists")
 			return
 		}
 		log.Error().Err(err).Msg("Failed to create user")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to create user",
-		})
+		service.WriteInternalError(w, "Failed to create user")
 		return
 	}
 
@@ -72,6 +97,7 @@ func CreateUserAction(w http.ResponseWriter, r *http.Request) {
 		"email":       user.Email,
 		"role":        user.Role,
 		"isActive":    user.IsActive,
+		"ssoManaged":  false,
 		"apiKey":      user.APIKey,
 		"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
 		"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
@@ -86,37 +112,50 @@ func GetUserAction(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		service.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": "Invalid user ID",
-		})
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
 		return
 	}
 
-	userModule := module.NewUser(db.NewUserRepository(db.GetDB()))
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
 	user, err := userModule.GetUser(userID)
 	if err != nil {
 		if errors.Is(err, module.ErrUserNotFound) {
-			service.WriteJSON(w, http.StatusNotFound, map[string]interface{}{
-				"errorMessage": "User not found",
-			})
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "User not found")
 			return
 		}
 		log.Error().Err(err).Msg("Failed to get user")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to get user",
-		})
+		service.WriteInternalError(w, "Failed to get user")
+		return
+	}
+
+	ssoManaged, err := userModule.IsSSOManaged(user.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user")
+		service.WriteInternalError(w, "Failed to get user")
+		return
+	}
+
+	pendingVerification, err := userModule.IsPendingVerification(user.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user")
+		service.WriteInternalError(w, "Failed to get user")
 		return
 	}
 
 	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"id":          user.ID,
-		"email":       user.Email,
-		"role":        user.Role,
-		"isActive":    user.IsActive,
-		"apiKey":      user.APIKey,
-		"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
-		"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
-		"updatedAt":   user.UpdatedAt.UTC().Format(time.RFC3339),
+		"id":                  user.ID,
+		"email":               user.Email,
+		"role":                user.Role,
+		"isActive":            user.IsActive,
+		"ssoManaged":          ssoManaged,
+		"pendingVerification": pendingVerification,
+		"apiKey":              user.APIKey,
+		"lastLoginAt":         user.LastLoginAt.UTC().Format(time.RFC3339),
+		"createdAt":           user.CreatedAt.UTC().Format(time.RFC3339),
+		"updatedAt":           user.UpdatedAt.UTC().Format(time.RFC3339),
 	})
 }
 
@@ -128,9 +167,7 @@ func UpdateUserAction(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		service.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": "Invalid user ID",
-		})
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
 		return
 	}
 
@@ -140,7 +177,10 @@ func UpdateUserAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userModule := module.NewUser(db.NewUserRepository(db.GetDB()))
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
 	user, err := userModule.UpdateUser(&module.UpdateUserOptions{
 		UserID:   userID,
 		Email:    req.Email,
@@ -151,30 +191,40 @@ func UpdateUserAction(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		if errors.Is(err, module.ErrUserNotFound) {
-			service.WriteJSON(w, http.StatusNotFound, map[string]interface{}{
-				"errorMessage": "User not found",
-			})
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "User not found")
 			return
 		}
 		if errors.Is(err, module.ErrUserEmailAlreadyExists) {
-			service.WriteJSON(w, http.StatusConflict, map[string]interface{}{
-				"errorMessage": "User with this email already exists",
-			})
+			service.WriteError(w, http.StatusConflict, service.ErrorCodeConflict, "User with this email already exists")
+			return
+		}
+		if errors.Is(err, module.ErrUserSSOManaged) {
+			service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Password cannot be changed for single sign-on users")
 			return
 		}
 		log.Error().Err(err).Msg("Failed to update user")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to update user",
-		})
+		service.WriteInternalError(w, "Failed to update user")
+		return
+	}
+
+	ssoManaged, err := userModule.IsSSOManaged(user.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update user")
+		service.WriteInternalError(w, "Failed to update user")
 		return
 	}
 
+	if !user.IsActive {
+		revokeUserSessions(user.ID)
+	}
+
 	log.Info().Int64("userID", user.ID).Msg("User updated successfully")
 	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
 		"id":          user.ID,
 		"email":       user.Email,
 		"role":        user.Role,
 		"isActive":    user.IsActive,
+		"ssoManaged":  ssoManaged,
 		"apiKey":      user.APIKey,
 		"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
 		"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
@@ -204,7 +254,10 @@ func ListUsersAction(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	userModule := module.NewUser(db.NewUserRepository(db.GetDB()))
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
 	result, err := userModule.ListUsers(&module.ListUsersOptions{
 		Limit:  limit,
 		Offset: offset,
@@ -212,23 +265,35 @@ func ListUsersAction(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list users")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to list users",
-		})
+		service.WriteInternalError(w, "Failed to list users")
+		return
+	}
+
+	userIDs := make([]int64, 0, len(result.Users))
+	for _, user := range result.Users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	statuses, err := userModule.GetUserStatuses(userIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list users")
+		service.WriteInternalError(w, "Failed to list users")
 		return
 	}
 
 	userList := make([]map[string]interface{}, 0, len(result.Users))
 	for _, user := range result.Users {
 		userList = append(userList, map[string]interface{}{
-			"id":          user.ID,
-			"email":       user.Email,
-			"role":        user.Role,
-			"isActive":    user.IsActive,
-			"apiKey":      user.APIKey,
-			"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
-			"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
-			"updatedAt":   user.UpdatedAt.UTC().Format(time.RFC3339),
+			"id":                  user.ID,
+			"email":               user.Email,
+			"role":                user.Role,
+			"isActive":            user.IsActive,
+			"ssoManaged":          statuses[user.ID].SSOManaged,
+			"apiKey":              user.APIKey,
+			"pendingVerification": statuses[user.ID].PendingVerification,
+			"lastLoginAt":         user.LastLoginAt.UTC().Format(time.RFC3339),
+			"createdAt":           user.CreatedAt.UTC().Format(time.RFC3339),
+			"updatedAt":           user.UpdatedAt.UTC().Format(time.RFC3339),
 		})
 	}
 
@@ -242,6 +307,38 @@ func ListUsersAction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ActivateUserAction handles manual activation of pending users
+func ActivateUserAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Activate user endpoint called")
+
+	userIDStr := chi.URLParam(r, "id")
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+	user, err := userModule.ActivateUser(userID)
+	if err != nil {
+		if errors.Is(err, module.ErrUserNotFound) {
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "User not found")
+			return
+		}
+		log.Error().Err(err).Msg("Failed to activate user")
+		service.WriteInternalError(w, "Failed to activate user")
+		return
+	}
+
+	log.Info().Int64("userID", user.ID).Msg("User activated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "User activated successfully",
+	})
+}
+
 // DeleteUserAction handles user deletion requests
 func DeleteUserAction(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Msg("Delete user endpoint called")
@@ -252,36 +349,491 @@ func DeleteUserAction(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		service.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": "Invalid user ID",
-		})
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Prevent self-deletion
 	if currentUser.ID == userID {
-		service.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": "You cannot delete your own account",
-		})
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "You cannot delete your own account")
 		return
 	}
 
-	userModule := module.NewUser(db.NewUserRepository(db.GetDB()))
-	err = userModule.DeleteUser(userID)
+	err = db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		if err := module.NewUser(tx.Users, tx.UsersMeta).DeleteUser(userID); err != nil {
+			return err
+		}
+		return tx.Sessions.DeleteByUserID(userID)
+	})
 	if err != nil {
 		if errors.Is(err, module.ErrUserNotFound) {
-			service.WriteJSON(w, http.StatusNotFound, map[string]interface{}{
-				"errorMessage": "User not found",
-			})
+			service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "User not found")
 			return
 		}
 		log.Error().Err(err).Msg("Failed to delete user")
-		service.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"errorMessage": "Failed to delete user",
-		})
+		service.WriteInternalError(w, "Failed to delete user")
 		return
 	}
 
 	log.Info().Int64("userID", userID).Msg("User deleted successfully")
 	service.WriteJSON(w, http.StatusNoContent, map[string]interface{}{})
 }
+
+// ListInactiveUsersAction handles listing of active users that did not login recently
+func ListInactiveUsersAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("List inactive users endpoint called")
+
+	days, ok := inactiveDays(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	offset := 0
+
+	if parsedLimit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+		limit = parsedLimit
+	}
+	if parsedOffset, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsedOffset >= 0 {
+		offset = parsedOffset
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+	users, err := userModule.ListInactiveUsers(time.Duration(days)*24*time.Hour, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list inactive users")
+		service.WriteInternalError(w, "Failed to list inactive users")
+		return
+	}
+
+	userList := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		userList = append(userList, map[string]interface{}{
+			"id":          user.ID,
+			"email":       user.Email,
+			"role":        user.Role,
+			"isActive":    user.IsActive,
+			"lastLoginAt": user.LastLoginAt.UTC().Format(time.RFC3339),
+			"createdAt":   user.CreatedAt.UTC().Format(time.RFC3339),
+			"updatedAt":   user.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"users": userList,
+		"days":  days,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
+
+// DeactivateInactiveUsersAction handles batch deactivation of users that did not login recently
+func DeactivateInactiveUsersAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Deactivate inactive users endpoint called")
+
+	currentUser, _ := middleware.GetUserFromContext(r.Context())
+
+	days, ok := inactiveDays(w, r)
+	if !ok {
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	// The current admin is never deactivated to avoid a lockout
+	users, err := userModule.DeactivateInactiveUsers(time.Duration(days)*24*time.Hour, currentUser.ID)
+
+	activityRepository := db.NewActivityRepository(db.GetDB())
+	ipAddress := service.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := fmt.Sprintf(`{"reason":"inactive","days":%d}`, days)
+
+	for _, user := range users {
+		entityID := user.ID
+		activityErr := activityRepository.Create(&db.Activity{
+			UserID:     &currentUser.ID,
+			UserEmail:  &currentUser.Email,
+			Action:     "user.deactivate",
+			EntityType: "user",
+			EntityID:   &entityID,
+			Details:    &details,
+			IPAddress:  &ipAddress,
+			UserAgent:  &userAgent,
+		})
+		if activityErr != nil {
+			log.Error().Err(activityErr).Int64("userID", user.ID).Msg("Failed to log user deactivation")
+		}
+
+		revokeUserSessions(user.ID)
+	}
+
+	if err != nil {
+		log.Error().Err(err).Int("count", len(users)).Msg("Failed to deactivate inactive users")
+		service.WriteInternalError(w, "Failed to deactivate inactive users")
+		return
+	}
+
+	log.Info().Int("count", len(users)).Int("days", days).Msg("Inactive users deactivated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Inactive users deactivated successfully",
+		"count":          len(users),
+	})
+}
+
+// revokeUserSessions logs a deactivated user out. Its API key stops working
+// as the authentication rejects inactive users.
+func revokeUserSessions(userID int64) {
+	sessionManager := module.NewSessionManager(
+		db.NewSessionRepository(db.GetDB()),
+		db.NewUserRepository(db.GetDB()),
+	)
+
+	if err := sessionManager.RevokeUserSessions(userID); err != nil {
+		log.Error().Err(err).Int64("userID", userID).Msg("Failed to revoke user sessions")
+	}
+}
+
+// GetUserPreferencesAction lists the preferences of the current user
+func GetUserPreferencesAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Get user preferences endpoint called")
+
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	preferences, err := userModule.GetPreferences(user.ID)
+	if err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to get user preferences")
+		service.WriteInternalError(w, "Failed to get user preferences")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"preferences": preferences,
+	})
+}
+
+// UpdateUserPreferencesAction sets preferences of the current user, keys
+// missing from the payload keep their value
+func UpdateUserPreferencesAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Update user preferences endpoint called")
+
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+		return
+	}
+
+	var preferences map[string]string
+
+	if err := service.DecodeJSON(r, &preferences); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	if err := validatePreferences(preferences); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	// Set every preference or none of them
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		return module.NewUser(tx.Users, tx.UsersMeta).SetPreferences(user.ID, preferences)
+	})
+
+	if err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to update user preferences")
+		service.WriteInternalError(w, "Failed to update user preferences")
+		return
+	}
+
+	log.Info().Int64("userID", user.ID).Int("count", len(preferences)).Msg("User preferences updated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "Preferences updated successfully",
+	})
+}
+
+// validatePreferences checks the preference keys and value sizes
+func validatePreferences(preferences map[string]string) error {
+	result := &service.ValidationErrors{}
+
+	if len(preferences) > MaxUserPreferences {
+		result.Errors = append(result.Errors, service.ValidationError{
+			Field:   "preferences",
+			Rule:    "max",
+			Message: fmt.Sprintf("At most %d preferences can be set at once", MaxUserPreferences),
+		})
+		return result
+	}
+
+	keys := make([]string, 0, len(preferences))
+	for key := range preferences {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !preferenceKeyPattern.MatchString(key) {
+			result.Errors = append(result.Errors, service.ValidationError{
+				Field:   key,
+				Rule:    "key",
+				Message: "Preference keys must be 1 to 50 lowercase letters or underscores",
+			})
+			continue
+		}
+		if len(preferences[key]) > MaxUserPreferenceBytes {
+			result.Errors = append(result.Errors, service.ValidationError{
+				Field:   key,
+				Rule:    "max",
+				Message: fmt.Sprintf("%s must not exceed %d bytes", key, MaxUserPreferenceBytes),
+			})
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result
+	}
+	return nil
+}
+
+// GetUIPreferencesAction lists the UI preferences of the current user
+func GetUIPreferencesAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Get UI preferences endpoint called")
+
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	preferences, err := userModule.GetUIPreferences(user.ID)
+	if err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to get UI preferences")
+		service.WriteInternalError(w, "Failed to get preferences")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"preferences": preferences,
+	})
+}
+
+// ReplaceUIPreferencesAction replaces the UI preferences of the current user,
+// preferences missing from the payload are removed
+func ReplaceUIPreferencesAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Replace UI preferences endpoint called")
+
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
+		return
+	}
+
+	var preferences map[string]string
+
+	if err := service.DecodeJSON(r, &preferences); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	if err := validateUIPreferences(preferences); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	err := db.WithTx(r.Context(), db.GetDB(), func(tx *db.Repos) error {
+		return module.NewUser(tx.Users, tx.UsersMeta).ReplaceUIPreferences(user.ID, preferences)
+	})
+
+	if err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to replace UI preferences")
+		service.WriteInternalError(w, "Failed to update preferences")
+		return
+	}
+
+	log.Info().Int64("userID", user.ID).Int("count", len(preferences)).Msg("UI preferences updated successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"preferences": preferences,
+	})
+}
+
+// validateUIPreferences checks that only UI preferences are set and their value sizes
+func validateUIPreferences(preferences map[string]string) error {
+	result := &service.ValidationErrors{}
+
+	keys := make([]string, 0, len(preferences))
+	for key := range preferences {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !module.IsUIPreference(key) {
+			result.Errors = append(result.Errors, service.ValidationError{
+				Field:   key,
+				Rule:    "oneof",
+				Message: fmt.Sprintf("Preference must be one of %s", strings.Join(module.UIPreferences, ", ")),
+			})
+			continue
+		}
+		if len(preferences[key]) > MaxUIPreferenceBytes {
+			result.Errors = append(result.Errors, service.ValidationError{
+				Field:   key,
+				Rule:    "max",
+				Message: fmt.Sprintf("%s must not exceed %d bytes", key, MaxUIPreferenceBytes),
+			})
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result
+	}
+	return nil
+}
+
+// ListUserMetaAction lists the metadata of a user, keys the application
+// writes itself are left out
+func ListUserMetaAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("List user meta endpoint called")
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	meta, err := userModule.ListMeta(userID)
+	if err != nil {
+		writeUserMetaError(w, err, "Failed to list user meta")
+		return
+	}
+
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"meta": meta,
+	})
+}
+
+// SetUserMetaAction sets a metadata entry of a user
+func SetUserMetaAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Set user meta endpoint called")
+
+	userID, key, ok := userMetaParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetUserMetaRequest
+	if err := service.DecodeAndValidate(r, &req); err != nil {
+		service.WriteValidationError(w, err)
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	if err := userModule.SetMeta(userID, key, req.Value); err != nil {
+		writeUserMetaError(w, err, "Failed to set user meta")
+		return
+	}
+
+	log.Info().Int64("userID", userID).Str("key", key).Msg("User meta set successfully")
+	service.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"successMessage": "User meta set successfully",
+	})
+}
+
+// DeleteUserMetaAction deletes a metadata entry of a user
+func DeleteUserMetaAction(w http.ResponseWriter, r *http.Request) {
+	log.Debug().Msg("Delete user meta endpoint called")
+
+	userID, key, ok := userMetaParams(w, r)
+	if !ok {
+		return
+	}
+
+	userModule := module.NewUser(
+		db.NewUserRepository(db.GetDB()),
+		db.NewUserMetaRepository(db.GetDB()),
+	)
+
+	if err := userModule.DeleteMeta(userID, key); err != nil {
+		writeUserMetaError(w, err, "Failed to delete user meta")
+		return
+	}
+
+	log.Info().Int64("userID", userID).Str("key", key).Msg("User meta deleted successfully")
+	service.WriteJSON(w, http.StatusNoContent, map[string]interface{}{})
+}
+
+// userMetaParams parses the user ID and metadata key URL parameters
+func userMetaParams(w http.ResponseWriter, r *http.Request) (int64, string, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Invalid user ID")
+		return 0, "", false
+	}
+
+	key := chi.URLParam(r, "key")
+	if !userMetaKeyPattern.MatchString(key) {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Meta keys must be 1 to 100 lowercase letters, digits or underscores")
+		return 0, "", false
+	}
+
+	return userID, key, true
+}
+
+// writeUserMetaError writes the response for a failed user metadata operation
+func writeUserMetaError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, module.ErrUserNotFound):
+		service.WriteError(w, http.StatusNotFound, service.ErrorCodeNotFound, "User not found")
+	case errors.Is(err, module.ErrUserMetaReserved):
+		service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "Meta key is reserved")
+	default:
+		log.Error().Err(err).Msg(message)
+		service.WriteInternalError(w, message)
+	}
+}
+
+// inactiveDays parses the days query parameter, it defaults to 90 days
+func inactiveDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	daysStr := r.URL.Query().Get("days")
+	if daysStr == "" {
+		return 90, true
+	}
+
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 || days > 3650 {
+		service.WriteError(w, http.StatusBadRequest, service.ErrorCodeBadRequest, "Days must be between 1 and 3650")
+		return 0, false
+	}
+
+	return days, true
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationUserPreferences tests setting and reading the preferences of the current user
+func TestIntegrationUserPreferences(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_user_preferences.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	_, err := db.GetDB().Exec(`
+		CREATE TABLE users_meta (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL,
+			value TEXT,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, key)
+		)
+	`)
+	require.NoError(t, err)
+
+	user := &db.User{ID: 7, Email: "user@example.com", Role: db.UserRoleUser, IsActive: true}
+	require.NoError(t, db.NewUserMetaRepository(db.GetDB()).Create(user.ID, "auth_provider", "oidc"))
+
+	request := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/users/me/settings", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUser, user))
+		w := httptest.NewRecorder()
+
+		if method == http.MethodPatch {
+			UpdateUserPreferencesAction(w, req)
+		} else {
+			GetUserPreferencesAction(w, req)
+		}
+		return w
+	}
+
+	t.Run("Set preferences", func(t *testing.T) {
+		w := request(http.MethodPatch, `{"theme":"dark","page_size":"50"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodPatch, `{"theme":"light"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Get preferences without internal metadata", func(t *testing.T) {
+		w := request(http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Preferences map[string]string `json:"preferences"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, map[string]string{"theme": "light", "page_size": "50"}, body.Preferences)
+	})
+
+	t.Run("Invalid preferences are rejected", func(t *testing.T) {
+		w := request(http.MethodPatch, `{"Theme":"dark","theme":"`+strings.Repeat("x", MaxUserPreferenceBytes+1)+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body struct {
+			Fields []map[string]string `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Fields, 2)
+		assert.Equal(t, "Theme", body.Fields[0]["field"])
+		assert.Equal(t, "theme", body.Fields[1]["field"])
+
+		w = request(http.MethodPatch, `{"theme":1}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Internal metadata can not be overwritten", func(t *testing.T) {
+		w := request(http.MethodPatch, `{"auth_provider":"local"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		meta, err := db.NewUserMetaRepository(db.GetDB()).Get(user.ID, "auth_provider")
+		require.NoError(t, err)
+		assert.Equal(t, "oidc", meta.Value)
+	})
+}
+
+// TestIntegrationUIPreferences tests replacing and reading the UI preferences of the current user
+func TestIntegrationUIPreferences(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_ui_preferences.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	_, err := db.GetDB().Exec(`
+		CREATE TABLE users_meta (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL,
+			value TEXT,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, key)
+		)
+	`)
+	require.NoError(t, err)
+
+	user := &db.User{ID: 7, Email: "user@example.com", Role: db.UserRoleUser, IsActive: true}
+	require.NoError(t, db.NewUserMetaRepository(db.GetDB()).Create(user.ID, "preference_sidebar", "collapsed"))
+
+	request := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/me/preferences", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUser, user))
+		w := httptest.NewRecorder()
+
+		if method == http.MethodPut {
+			ReplaceUIPreferencesAction(w, req)
+		} else {
+			GetUIPreferencesAction(w, req)
+		}
+		return w
+	}
+
+	preferences := func() map[string]string {
+		w := request(http.MethodGet, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Preferences map[string]string `json:"preferences"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Preferences
+	}
+
+	t.Run("Replace preferences", func(t *testing.T) {
+		w := request(http.MethodPut, `{"theme":"dark","page_size":"50"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]string{"theme": "dark", "page_size": "50"}, preferences())
+
+		w = request(http.MethodPut, `{"theme":"light"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]string{"theme": "light"}, preferences())
+	})
+
+	t.Run("Only UI preferences are accepted", func(t *testing.T) {
+		w := request(http.MethodPut, `{"sidebar":"open","theme":"`+strings.Repeat("x", MaxUIPreferenceBytes+1)+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body struct {
+			Fields []map[string]string `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Fields, 2)
+		assert.Equal(t, "sidebar", body.Fields[0]["field"])
+		assert.Equal(t, "theme", body.Fields[1]["field"])
+
+		assert.Equal(t, map[string]string{"theme": "light"}, preferences())
+	})
+
+	t.Run("Other preferences are kept", func(t *testing.T) {
+		meta, err := db.NewUserMetaRepository(db.GetDB()).Get(user.ID, "preference_sidebar")
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+		assert.Equal(t, "collapsed", meta.Value)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the database",
+	Long:  `Write a consistent snapshot of the database into a tar.gz archive, the server can keep running`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		out, _ := cmd.Flags().GetString("out")
+
+		loadConfig(cmd)
+		conn := connectDatabase()
+		defer conn.Close()
+
+		if err := newCLIBackupManager(conn.DB).CreateBackup(out); err != nil {
+			log.Fatal().Err(err).Msg("Failed to create backup")
+		}
+
+		fmt.Printf("Backup written to %s\n", out)
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the database from a backup",
+	Long:  `Restore the database from a tar.gz archive created by the backup command, stop the server before restoring`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		in, _ := cmd.Flags().GetString("in")
+		force, _ := cmd.Flags().GetBool("force")
+
+		loadConfig(cmd)
+
+		// SQLite is restored by replacing the database file, so it must not be held open
+		var database *sql.DB
+		if viper.GetString("app.database.driver") != "sqlite" {
+			conn := connectDatabase()
+			defer conn.Close()
+			database = conn.DB
+		}
+
+		manifest, err := newCLIBackupManager(database).RestoreBackup(in, force)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to restore backup")
+		}
+
+		fmt.Printf(
+			"Restored %s backup created at %s (schema version %s)\n",
+			manifest.Driver,
+			manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"),
+			manifest.SchemaVersion,
+		)
+	},
+}
+
+// newCLIBackupManager creates a backup manager from the configuration
+func newCLIBackupManager(database *sql.DB) *service.BackupManager {
+	return service.NewBackupManager(
+		database,
+		service.BackupConfig{
+			Driver:     viper.GetString("app.database.driver"),
+			Host:       viper.GetString("app.database.host"),
+			Port:       viper.GetInt("app.database.port"),
+			Username:   viper.GetString("app.database.username"),
+			Password:   viper.GetString("app.database.password"),
+			Database:   viper.GetString("app.database.name"),
+			DataSource: viper.GetString("app.database.datasource"),
+			AppVersion: Version,
+		},
+		viper.GetString("app.backup.dir"),
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	backupCmd.Flags().StringVarP(
+		&config,
+		"config",
+		"c",
+		"config.prod.yml",
+		"Absolute path to config file (required)",
+	)
+	backupCmd.MarkFlagRequired("config")
+	backupCmd.Flags().StringP(
+		"out",
+		"o",
+		"backup.tar.gz",
+		"Path of the backup archive to write",
+	)
+
+	restoreCmd.Flags().StringVarP(
+		&config,
+		"config",
+		"c",
+		"config.prod.yml",
+		"Absolute path to config file (required)",
+	)
+	restoreCmd.MarkFlagRequired("config")
+	restoreCmd.Flags().StringP(
+		"in",
+		"i",
+		"",
+		"Path of the backup archive to restore (required)",
+	)
+	restoreCmd.MarkFlagRequired("in")
+	restoreCmd.Flags().Bool(
+		"force",
+		false,
+		"Overwrite a database that already has data",
+	)
+}
@@ -5,6 +5,11 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
 	"github.com/clivern/tut/core"
 	"github.com/clivern/tut/db"
 	"github.com/clivern/tut/migration"
@@ -24,44 +29,9 @@ var migrateUpCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Run all pending migrations",
 	Run: func(cmd *cobra.Command, _ []string) {
-		configFile, _ := cmd.Flags().GetString("config")
-
-		if err := core.Load(configFile); err != nil {
-			log.Fatal().Err(err).Msg("Failed to load configuration")
-		}
-
-		if err := core.SetupLogging(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to setup logging")
-		}
-
-		// Initialize database connection
-		dbConfig := db.Config{
-			Driver:          viper.GetString("app.database.driver"),
-			Host:            viper.GetString("app.database.host"),
-			Port:            viper.GetInt("app.database.port"),
-			Username:        viper.GetString("app.database.username"),
-			Password:        viper.GetString("app.database.password"),
-			Database:        viper.GetString("app.database.name"),
-			MaxOpenConns:    viper.GetInt("app.database.max_open_conns"),
-			MaxIdleConns:    viper.GetInt("app.database.max_idle_conns"),
-			ConnMaxLifetime: viper.GetInt("app.database.conn_max_lifetime"),
-			DataSource:      viper.GetString("app.database.datasource"),
-		}
-
-		conn, err := db.NewConnection(dbConfig)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to connect to database")
-		}
+		conn, mgr := newMigrationManager(cmd)
 		defer conn.Close()
 
-		// Create migration manager
-		mgr := migration.NewManager(conn.DB, conn.Driver)
-
-		// Register all migrations
-		for _, m := range migration.GetAll() {
-			mgr.Register(m)
-		}
-
 		// Run migrations
 		if err := mgr.Up(); err != nil {
 			log.Fatal().Err(err).Msg("Failed to run migrations")
@@ -75,50 +45,33 @@ var migrateDownCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Roll back the last migration",
 	Run: func(cmd *cobra.Command, _ []string) {
-		configFile, _ := cmd.Flags().GetString("config")
+		conn, mgr := newMigrationManager(cmd)
+		defer conn.Close()
 
-		if err := core.Load(configFile); err != nil {
-			log.Fatal().Err(err).Msg("Failed to load configuration")
+		// Roll back migration
+		if err := mgr.Down(); err != nil {
+			log.Fatal().Err(err).Msg("Failed to roll back migration")
 		}
 
-		if err := core.SetupLogging(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to setup logging")
-		}
+		log.Info().Msg("Rollback completed successfully")
+	},
+}
 
-		// Initialize database connection
-		dbConfig := db.Config{
-			Driver:          viper.GetString("app.database.driver"),
-			Host:            viper.GetString("app.database.host"),
-			Port:            viper.GetInt("app.database.port"),
-			Username:        viper.GetString("app.database.username"),
-			Password:        viper.GetString("app.database.password"),
-			Database:        viper.GetString("app.database.name"),
-			MaxOpenConns:    viper.GetInt("app.database.max_open_conns"),
-			MaxIdleConns:    viper.GetInt("app.database.max_idle_conns"),
-			ConnMaxLifetime: viper.GetInt("app.database.conn_max_lifetime"),
-			DataSource:      viper.GetString("app.database.datasource"),
-		}
+var migrateRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back the last N migrations",
+	Run: func(cmd *cobra.Command, _ []string) {
+		steps, _ := cmd.Flags().GetInt("steps")
 
-		conn, err := db.NewConnection(dbConfig)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to connect to database")
-		}
+		conn, mgr := newMigrationManager(cmd)
 		defer conn.Close()
 
-		// Create migration manager
-		mgr := migration.NewManager(conn.DB, conn.Driver)
-
-		// Register all migrations
-		for _, m := range migration.GetAll() {
-			mgr.Register(m)
+		// Roll back migrations
+		if err := mgr.Rollback(steps); err != nil {
+			log.Fatal().Err(err).Msg("Failed to roll back migrations")
 		}
 
-		// Roll back migration
-		if err := mgr.Down(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to roll back migration")
-		}
-
-		log.Info().Msg("Rollback completed successfully")
+		log.Info().Int("steps", steps).Msg("Rollback completed successfully")
 	},
 }
 
@@ -126,55 +79,94 @@ var migrateStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show migration status",
 	Run: func(cmd *cobra.Command, _ []string) {
-		configFile, _ := cmd.Flags().GetString("config")
+		conn, mgr := newMigrationManager(cmd)
+		defer conn.Close()
 
-		if err := core.Load(configFile); err != nil {
-			log.Fatal().Err(err).Msg("Failed to load configuration")
+		// Show status
+		statuses, err := mgr.Status()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to get migration status")
 		}
 
-		if err := core.SetupLogging(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to setup logging")
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tDESCRIPTION\tSTATUS")
+		for _, status := range statuses {
+			state := "PENDING"
+			if status.Applied {
+				state = "APPLIED"
+				if status.AppliedAt != nil {
+					state = fmt.Sprintf("APPLIED %s", status.AppliedAt.Format(time.RFC3339))
+				}
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", status.Version, status.Description, state)
 		}
+		writer.Flush()
+	},
+}
 
-		// Initialize database connection
-		dbConfig := db.Config{
-			Driver:          viper.GetString("app.database.driver"),
-			Host:            viper.GetString("app.database.host"),
-			Port:            viper.GetInt("app.database.port"),
-			Username:        viper.GetString("app.database.username"),
-			Password:        viper.GetString("app.database.password"),
-			Database:        viper.GetString("app.database.name"),
-			MaxOpenConns:    viper.GetInt("app.database.max_open_conns"),
-			MaxIdleConns:    viper.GetInt("app.database.max_idle_conns"),
-			ConnMaxLifetime: viper.GetInt("app.database.conn_max_lifetime"),
-			DataSource:      viper.GetString("app.database.datasource"),
-		}
+// newMigrationManager loads the configs, connects to the database and
+// returns a migration manager with all migrations registered
+func newMigrationManager(cmd *cobra.Command) (*db.Connection, *migration.Manager) {
+	loadConfig(cmd)
+	conn := connectDatabase()
 
-		conn, err := db.NewConnection(dbConfig)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to connect to database")
-		}
-		defer conn.Close()
+	// Create migration manager
+	mgr := migration.NewManager(conn.DB, conn.Driver)
 
-		// Create migration manager
-		mgr := migration.NewManager(conn.DB, conn.Driver)
+	// Register all migrations
+	for _, m := range migration.GetAll() {
+		mgr.Register(m)
+	}
 
-		// Register all migrations
-		for _, m := range migration.GetAll() {
-			mgr.Register(m)
-		}
+	return conn, mgr
+}
 
-		// Show status
-		if err := mgr.Status(); err != nil {
-			log.Fatal().Err(err).Msg("Failed to get migration status")
-		}
-	},
+// loadConfig loads the configs from the config flag and sets up logging
+func loadConfig(cmd *cobra.Command) {
+	configFile, _ := cmd.Flags().GetString("config")
+
+	if err := core.Load(configFile); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load configuration")
+	}
+
+	if err := core.SetupLogging(); err != nil {
+		log.Fatal().Err(err).Msg("Failed to setup logging")
+	}
+}
+
+// connectDatabase connects to the configured database
+func connectDatabase() *db.Connection {
+	dbConfig := db.Config{
+		Driver:          viper.GetString("app.database.driver"),
+		Host:            viper.GetString("app.database.host"),
+		Port:            viper.GetInt("app.database.port"),
+		Username:        viper.GetString("app.database.username"),
+		Password:        viper.GetString("app.database.password"),
+		Database:        viper.GetString("app.database.name"),
+		MaxOpenConns:    viper.GetInt("app.database.max_open_conns"),
+		MaxIdleConns:    viper.GetInt("app.database.max_idle_conns"),
+		ConnMaxLifetime: viper.GetInt("app.database.conn_max_lifetime"),
+		DataSource:      viper.GetString("app.database.datasource"),
+
+		SlowQueryThreshold: viper.GetInt("app.database.slow_query_threshold_ms"),
+		SQLiteJournalMode:  viper.GetString("app.database.sqlite_journal_mode"),
+		SQLiteBusyTimeout:  viper.GetInt("app.database.sqlite_busy_timeout_ms"),
+		SQLiteSynchronous:  viper.GetString("app.database.sqlite_synchronous"),
+	}
+
+	conn, err := db.NewConnection(dbConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+
+	return conn
 }
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 	migrateCmd.AddCommand(migrateUpCmd)
 	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateRollbackCmd)
 	migrateCmd.AddCommand(migrateStatusCmd)
 
 	migrateUpCmd.Flags().StringVarP(
@@ -193,6 +185,19 @@ func init() {
 		"Absolute path to config file (required)",
 	)
 	migrateDownCmd.MarkFlagRequired("config")
+	migrateRollbackCmd.Flags().StringVarP(
+		&config,
+		"config",
+		"c",
+		"config.prod.yml",
+		"Absolute path to config file (required)",
+	)
+	migrateRollbackCmd.MarkFlagRequired("config")
+	migrateRollbackCmd.Flags().Int(
+		"steps",
+		1,
+		"Number of migrations to roll back",
+	)
 	migrateStatusCmd.Flags().StringVarP(
 		&config,
 		"config",
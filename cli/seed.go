@@ -0,0 +1,112 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Seed demo data for development and testing",
+	Long:  `Create deterministic demo users, seeded users are tagged so they can be wiped without touching real data`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		users, _ := cmd.Flags().GetInt("users")
+		wipe, _ := cmd.Flags().GetBool("wipe")
+		force, _ := cmd.Flags().GetBool("force")
+
+		conn, mgr := newMigrationManager(cmd)
+		defer conn.Close()
+
+		// Run pending migrations
+		if err := mgr.Up(); err != nil {
+			log.Fatal().Err(err).Msg("Failed to run migrations")
+		}
+
+		setupModule := module.NewSetup(
+			db.NewOptionRepository(conn.DB),
+			db.NewUserRepository(conn.DB),
+		)
+		installed, err := setupModule.IsInstalled()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to check the installation state")
+		}
+		if installed && !force {
+			log.Fatal().Msg("Application is installed, use --force to seed demo data anyway")
+		}
+
+		var seeded []*module.SeededUser
+		var wiped int
+
+		err = db.WithTx(context.Background(), conn.DB, func(tx *db.Repos) error {
+			seeder := module.NewSeeder(tx)
+
+			var err error
+			if wipe {
+				if wiped, err = seeder.Wipe(); err != nil {
+					return err
+				}
+			}
+
+			seeded, err = seeder.SeedUsers(users)
+			return err
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to seed demo data")
+		}
+
+		if wipe {
+			fmt.Printf("Wiped %d seeded users\n", wiped)
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "EMAIL\tPASSWORD\tROLE\tAPI KEY\tSTATUS")
+		for _, user := range seeded {
+			status := "EXISTING"
+			if user.Created {
+				status = "CREATED"
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", user.User.Email, user.Password, user.User.Role, user.User.APIKey, status)
+		}
+		writer.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().StringVarP(
+		&config,
+		"config",
+		"c",
+		"config.prod.yml",
+		"Absolute path to config file (required)",
+	)
+	seedCmd.MarkFlagRequired("config")
+	seedCmd.Flags().Int(
+		"users",
+		5,
+		"Number of demo users to create",
+	)
+	seedCmd.Flags().Bool(
+		"wipe",
+		false,
+		"Delete previously seeded data before seeding",
+	)
+	seedCmd.Flags().Bool(
+		"force",
+		false,
+		"Seed even when the application is installed",
+	)
+}
@@ -7,6 +7,7 @@ package cli
 import (
 	"fmt"
 
+	"github.com/clivern/tut/api"
 	"github.com/clivern/tut/core"
 
 	"github.com/spf13/cobra"
@@ -26,6 +27,9 @@ var serverCmd = &cobra.Command{
 			panic(err.Error())
 		}
 
+		// Backups created through the API record the running version
+		api.AppVersion = Version
+
 		// Setup and configure the HTTP server
 		r := core.Setup(Static)
 
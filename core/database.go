@@ -16,6 +16,8 @@ type Database struct {
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	// Queries slower than this (in milliseconds) are logged
+	SlowQueryThreshold int `mapstructure:"slow_query_threshold_ms"`
 	// SQLite specific
 	DataSource string `mapstructure:"datasource"`
 }
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/drone/envsubst"
 	"github.com/spf13/viper"
@@ -15,6 +16,18 @@ import (
 
 // Load reads and parses the configuration file
 func Load(configPath string) error {
+	if err := readConfig(configPath, viper.GetViper()); err != nil {
+		return err
+	}
+
+	viper.SetDefault("config", configPath)
+
+	return nil
+}
+
+// readConfig reads the configuration file into v, environment variables
+// in the file are substituted first
+func readConfig(configPath string, v *viper.Viper) error {
 	configUnparsed, err := os.ReadFile(configPath)
 
 	if err != nil {
@@ -27,14 +40,33 @@ func Load(configPath string) error {
 		return fmt.Errorf("error while parsing config file [%s]: %w", configPath, err)
 	}
 
-	viper.SetConfigType("yaml")
-	err = viper.ReadConfig(bytes.NewBuffer([]byte(configParsed)))
+	v.SetConfigType("yaml")
+	err = v.ReadConfig(bytes.NewBuffer([]byte(configParsed)))
 
 	if err != nil {
 		return fmt.Errorf("error while loading configs [%s]: %w", configPath, err)
 	}
 
-	viper.SetDefault("config", configPath)
-
 	return nil
 }
+
+// GetList returns a list config value. The value can be a YAML list or a
+// comma separated string so it can be set from an environment variable.
+func GetList(key string) []string {
+	return getList(viper.GetViper(), key)
+}
+
+// getList returns a list config value of v
+func getList(v *viper.Viper, key string) []string {
+	list := []string{}
+
+	for _, value := range v.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnitGetList tests reading list configs from YAML lists and comma separated strings
+func TestUnitGetList(t *testing.T) {
+	defer viper.Set("test.list", nil)
+
+	viper.Set("test.list", "")
+	assert.Equal(t, []string{}, GetList("test.list"))
+
+	viper.Set("test.list", "10.0.0.0/8, 192.168.1.0/24,")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.0/24"}, GetList("test.list"))
+
+	viper.Set("test.list", []string{"10.0.0.0/8", "127.0.0.1,::1"})
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1", "::1"}, GetList("test.list"))
+}
@@ -9,7 +9,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/clivern/tut/service"
 
@@ -18,6 +21,22 @@ import (
 	"github.com/spf13/viper"
 )
 
+// modulePath is stripped from function names to get the package of a log line
+const modulePath = "github.com/clivern/tut/"
+
+// logLevels holds the base log level and the per package overrides
+type logLevels struct {
+	base      zerolog.Level
+	overrides map[string]zerolog.Level
+}
+
+var (
+	// currentLogLevels is swapped as a whole so a reload never mixes settings
+	currentLogLevels atomic.Pointer[logLevels]
+	// callerPackages caches the package of each logging call site
+	callerPackages sync.Map
+)
+
 // SetupLogging configures the logging system based on viper configuration
 func SetupLogging() error {
 	var writer io.Writer
@@ -31,19 +50,10 @@ func SetupLogging() error {
 			}
 		}
 
-		// Create log file if it doesn't exist to ensure it's writable
-		if !service.FileExists(viper.GetString("app.log.output")) {
-			f, err := os.Create(viper.GetString("app.log.output"))
-			if err != nil {
-				return fmt.Errorf("error while creating log file [%s]: %w", viper.GetString("app.log.output"), err)
-			}
-			f.Close()
-		}
-
-		f, err := os.OpenFile(
+		f, err := service.NewRotatingFile(
 			viper.GetString("app.log.output"),
-			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-			0775,
+			viper.GetInt64("app.log.max_size_mb")*1024*1024,
+			viper.GetInt("app.log.max_backups"),
 		)
 		if err != nil {
 			return fmt.Errorf("error opening log file: %w", err)
@@ -53,30 +63,138 @@ func SetupLogging() error {
 		writer = os.Stdout
 	}
 
+	var logger zerolog.Logger
 	if viper.GetString("app.log.format") == "json" {
-		log.Logger = zerolog.New(writer).With().Timestamp().Logger()
+		logger = zerolog.New(writer)
 	} else {
-		log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: writer}).With().Timestamp().Logger()
+		logger = zerolog.New(zerolog.ConsoleWriter{Out: writer})
+	}
+	log.Logger = logger.With().Timestamp().Logger().Hook(zerolog.HookFunc(filterPackageLevel))
+
+	level, err := ParseLogLevel(viper.GetString("app.log.level"))
+	if err != nil {
+		level = zerolog.InfoLevel
 	}
 
-	level := strings.ToLower(viper.GetString("app.log.level"))
+	overrides, err := ParsePackageLogLevels(GetList("app.log.levels"))
+	if err != nil {
+		return err
+	}
+
+	SetLogLevels(level, overrides)
+
+	return nil
+}
 
-	switch level {
+// ParseLogLevel converts a configured log level name into a zerolog level
+func ParseLogLevel(name string) (zerolog.Level, error) {
+	switch strings.ToLower(name) {
 	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		return zerolog.DebugLevel, nil
 	case "info":
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		return zerolog.InfoLevel, nil
 	case "warn", "warning":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+		return zerolog.WarnLevel, nil
 	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+		return zerolog.ErrorLevel, nil
 	case "fatal":
-		zerolog.SetGlobalLevel(zerolog.FatalLevel)
+		return zerolog.FatalLevel, nil
 	case "panic":
-		zerolog.SetGlobalLevel(zerolog.PanicLevel)
-	default:
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		return zerolog.PanicLevel, nil
 	}
 
-	return nil
+	return zerolog.InfoLevel, fmt.Errorf("unknown log level [%s]", name)
+}
+
+// ParsePackageLogLevels parses per package levels like api=debug or db=warn
+func ParsePackageLogLevels(items []string) (map[string]zerolog.Level, error) {
+	overrides := make(map[string]zerolog.Level, len(items))
+
+	for _, item := range items {
+		pkg, name, ok := strings.Cut(item, "=")
+		pkg = strings.TrimSpace(pkg)
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid package log level [%s], expected package=level", item)
+		}
+
+		level, err := ParseLogLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid package log level [%s]: %w", item, err)
+		}
+		overrides[pkg] = level
+	}
+
+	return overrides, nil
+}
+
+// SetLogLevels sets the base log level and the per package overrides
+func SetLogLevels(base zerolog.Level, overrides map[string]zerolog.Level) {
+	currentLogLevels.Store(&logLevels{base: base, overrides: overrides})
+
+	// The global level lets through anything a package may log, the hook
+	// drops the rest
+	lowest := base
+	for _, level := range overrides {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// filterPackageLevel discards events below the level of the package logging them
+func filterPackageLevel(e *zerolog.Event, level zerolog.Level, _ string) {
+	levels := currentLogLevels.Load()
+	if levels == nil || len(levels.overrides) == 0 {
+		return
+	}
+
+	threshold := levels.base
+	if override, ok := levels.overrides[callerPackage()]; ok {
+		threshold = override
+	}
+
+	if level < threshold {
+		e.Discard()
+	}
+}
+
+// callerPackage returns the package, relative to the module, of the first
+// function on the stack outside zerolog and this hook
+func callerPackage() string {
+	var pcs [16]uintptr
+	// Skip runtime.Callers, callerPackage and filterPackageLevel
+	n := runtime.Callers(3, pcs[:])
+
+	for _, pc := range pcs[:n] {
+		if pkg, ok := callerPackages.Load(pc); ok {
+			return pkg.(string)
+		}
+
+		fn := runtime.FuncForPC(pc - 1)
+		if fn == nil {
+			continue
+		}
+
+		name := fn.Name()
+		if strings.HasPrefix(name, "github.com/rs/zerolog") {
+			continue
+		}
+
+		pkg := packageOf(name)
+		callerPackages.Store(pc, pkg)
+		return pkg
+	}
+
+	return ""
+}
+
+// packageOf returns the package of a function name like
+// github.com/clivern/tut/api.LoginAction, relative to the module
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		function = function[:slash+1+dot]
+	}
+	return strings.TrimPrefix(function, modulePath)
 }
@@ -0,0 +1,56 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnitParsePackageLogLevels tests parsing per package log levels
+func TestUnitParsePackageLogLevels(t *testing.T) {
+	overrides, err := ParsePackageLogLevels([]string{"api=debug", " db = warn "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]zerolog.Level{"api": zerolog.DebugLevel, "db": zerolog.WarnLevel}, overrides)
+
+	_, err = ParsePackageLogLevels([]string{"api"})
+	assert.Error(t, err)
+
+	_, err = ParsePackageLogLevels([]string{"api=loud"})
+	assert.Error(t, err)
+}
+
+// TestUnitPackageOf tests resolving the package of a function name
+func TestUnitPackageOf(t *testing.T) {
+	assert.Equal(t, "api", packageOf("github.com/clivern/tut/api.LoginAction"))
+	assert.Equal(t, "middleware", packageOf("github.com/clivern/tut/middleware.Logger.func1"))
+	assert.Equal(t, "db", packageOf("github.com/clivern/tut/db.(*UserRepository).Create"))
+	assert.Equal(t, "sdk/v1", packageOf("github.com/clivern/tut/sdk/v1.NewClient"))
+	assert.Equal(t, "main", packageOf("main.main"))
+}
+
+// TestUnitPackageLogLevels tests that package overrides win over the base level
+func TestUnitPackageLogLevels(t *testing.T) {
+	previousLevel := zerolog.GlobalLevel()
+	defer SetLogLevels(previousLevel, nil)
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(zerolog.HookFunc(filterPackageLevel))
+
+	SetLogLevels(zerolog.InfoLevel, map[string]zerolog.Level{"core": zerolog.DebugLevel})
+	logger.Debug().Msg("verbose core")
+	assert.Contains(t, buf.String(), "verbose core")
+
+	buf.Reset()
+	SetLogLevels(zerolog.DebugLevel, map[string]zerolog.Level{"core": zerolog.WarnLevel, "api": zerolog.DebugLevel})
+	logger.Info().Msg("quiet core")
+	logger.Warn().Msg("loud core")
+	assert.NotContains(t, buf.String(), "quiet core")
+	assert.Contains(t, buf.String(), "loud core")
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// DefaultRegisterRateLimit is the registrations allowed per client IP and hour
+const DefaultRegisterRateLimit = 5
+
+// reloadableKeys are the configs Reload applies without a restart
+var reloadableKeys = []string{
+	"app.log.level",
+	"app.log.levels",
+	"app.rate_limit.register",
+	"app.max_json_body_bytes",
+	"app.trusted_proxies",
+	"app.trust_proxy",
+}
+
+// immutableKeys are the configs that only change with a restart, Reload
+// rejects a file that changes any of them
+var immutableKeys = []string{
+	"app.port",
+	"app.hostname",
+	"app.log.format",
+	"app.log.output",
+	"app.log.max_size_mb",
+	"app.log.max_backups",
+	"app.tls.status",
+	"app.tls.crt_path",
+	"app.tls.key_path",
+	"app.tls.http_port",
+	"app.tls.autocert.status",
+	"app.tls.autocert.hostnames",
+	"app.database.driver",
+	"app.database.host",
+	"app.database.port",
+	"app.database.name",
+	"app.database.username",
+	"app.database.password",
+	"app.database.datasource",
+}
+
+var (
+	// reloadMutex keeps concurrent reloads from interleaving
+	reloadMutex sync.Mutex
+	// registerRateLimiter limits registrations, Reload changes its limit
+	registerRateLimiter *middleware.RateLimiter
+)
+
+// runtimeConfig holds the validated reloadable settings
+type runtimeConfig struct {
+	logLevel          zerolog.Level
+	logOverrides      map[string]zerolog.Level
+	registerRateLimit int
+	maxJSONBodyBytes  int64
+	trustedProxies    []string
+}
+
+// loadRuntimeConfig validates the reloadable settings of v
+func loadRuntimeConfig(v *viper.Viper) (*runtimeConfig, error) {
+	config := &runtimeConfig{
+		registerRateLimit: v.GetInt("app.rate_limit.register"),
+		maxJSONBodyBytes:  v.GetInt64("app.max_json_body_bytes"),
+		trustedProxies:    getList(v, "app.trusted_proxies"),
+	}
+
+	level, err := ParseLogLevel(v.GetString("app.log.level"))
+	if err != nil {
+		return nil, err
+	}
+	config.logLevel = level
+
+	config.logOverrides, err = ParsePackageLogLevels(getList(v, "app.log.levels"))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.registerRateLimit < 1 {
+		return nil, fmt.Errorf("app.rate_limit.register must be at least 1")
+	}
+
+	if config.maxJSONBodyBytes < 1 {
+		return nil, fmt.Errorf("app.max_json_body_bytes must be at least 1")
+	}
+
+	if len(config.trustedProxies) == 0 && v.GetBool("app.trust_proxy") {
+		config.trustedProxies = service.PrivateNetworks
+	}
+	for _, proxy := range config.trustedProxies {
+		if _, err := service.ParseNetwork(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// apply switches the running server to the settings, they are validated
+// so none of the setters can fail halfway
+func (c *runtimeConfig) apply() {
+	SetLogLevels(c.logLevel, c.logOverrides)
+	middleware.SetMaxJSONBodyBytes(c.maxJSONBodyBytes)
+	service.SetTrustedProxies(c.trustedProxies)
+
+	if registerRateLimiter != nil {
+		registerRateLimiter.SetLimit(c.registerRateLimit)
+	}
+}
+
+// Reload re-reads the configuration file and applies the reloadable settings.
+// Either all of them switch or, when the file is invalid or changes a setting
+// that needs a restart, none do. It returns the changed keys.
+func Reload(activity *db.Activity) ([]string, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	changed, err := reload(viper.GetString("config"))
+
+	if err != nil {
+		log.Error().Err(err).Msg("Configuration reload rejected")
+	} else {
+		log.Info().Strs("changed", changed).Msg("Configuration reloaded")
+	}
+
+	recordReload(activity, changed, err)
+
+	return changed, err
+}
+
+// reload applies the reloadable settings of the configuration file
+func reload(configPath string) ([]string, error) {
+	next := viper.New()
+	if err := readConfig(configPath, next); err != nil {
+		return nil, fmt.Errorf("%w: %s", module.ErrConfigInvalid, err)
+	}
+
+	immutable := changedKeys(next, immutableKeys)
+	if len(immutable) > 0 {
+		return nil, fmt.Errorf("%w: %s", module.ErrConfigImmutable, strings.Join(immutable, ", "))
+	}
+
+	config, err := loadRuntimeConfig(next)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", module.ErrConfigInvalid, err)
+	}
+
+	changed := changedKeys(next, reloadableKeys)
+	for _, key := range changed {
+		viper.Set(key, next.Get(key))
+	}
+	config.apply()
+
+	return changed, nil
+}
+
+// changedKeys returns the keys whose value in next differs from the running config
+func changedKeys(next *viper.Viper, keys []string) []string {
+	changed := []string{}
+
+	for _, key := range keys {
+		if fmt.Sprint(viper.Get(key)) != fmt.Sprint(next.Get(key)) {
+			changed = append(changed, key)
+		}
+	}
+
+	return changed
+}
+
+// recordReload logs the reload result as an activity
+func recordReload(activity *db.Activity, changed []string, reloadErr error) {
+	conn := db.GetDB()
+	if conn == nil {
+		return
+	}
+
+	if activity == nil {
+		activity = &db.Activity{}
+	}
+
+	result := map[string]interface{}{"status": "applied", "changed": changed}
+	if reloadErr != nil {
+		result = map[string]interface{}{"status": "rejected", "error": reloadErr.Error()}
+	}
+
+	details, err := json.Marshal(result)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode configuration reload details")
+		return
+	}
+	detailsStr := string(details)
+
+	activity.Action = "config.reload"
+	activity.EntityType = "config"
+	activity.Details = &detailsStr
+
+	if err := db.NewActivityRepository(conn).Create(activity); err != nil {
+		log.Error().Err(err).Msg("Failed to log configuration reload")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadTestConfig is the config file template of the reload tests
+const reloadTestConfig = `app:
+  port: 8000
+  max_json_body_bytes: %d
+  rate_limit:
+    register: 5
+  trusted_proxies: "%s"
+  log:
+    level: %s
+  database:
+    driver: sqlite
+    datasource: %s
+`
+
+// TestUnitReload tests reloading the configuration file at runtime
+func TestUnitReload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeConfig := func(maxBytes int, proxies, level, datasource string) {
+		content := []byte(fmt.Sprintf(reloadTestConfig, maxBytes, proxies, level, datasource))
+		require.NoError(t, os.WriteFile(configPath, content, 0600))
+	}
+
+	previousLevel := zerolog.GlobalLevel()
+	defer func() {
+		viper.Reset()
+		zerolog.SetGlobalLevel(previousLevel)
+		middleware.SetMaxJSONBodyBytes(0)
+		service.SetTrustedProxies(nil)
+	}()
+
+	writeConfig(1024, "", "info", "/tmp/tut.db")
+	require.NoError(t, Load(configPath))
+
+	t.Run("Reloadable settings are applied", func(t *testing.T) {
+		writeConfig(2048, "10.0.0.0/8", "warn", "/tmp/tut.db")
+
+		changed, err := reload(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"app.log.level", "app.max_json_body_bytes", "app.trusted_proxies"}, changed)
+		assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+		assert.Equal(t, int64(2048), middleware.GetMaxJSONBodyBytes())
+		assert.Equal(t, "warn", viper.GetString("app.log.level"))
+	})
+
+	t.Run("Invalid settings change nothing", func(t *testing.T) {
+		writeConfig(4096, "not-a-network", "error", "/tmp/tut.db")
+
+		_, err := reload(configPath)
+		assert.ErrorIs(t, err, module.ErrConfigInvalid)
+		assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+		assert.Equal(t, int64(2048), middleware.GetMaxJSONBodyBytes())
+		assert.Equal(t, 2048, viper.GetInt("app.max_json_body_bytes"))
+	})
+
+	t.Run("Immutable settings are rejected", func(t *testing.T) {
+		writeConfig(4096, "", "error", "/tmp/other.db")
+
+		_, err := reload(configPath)
+		assert.ErrorIs(t, err, module.ErrConfigImmutable)
+		assert.ErrorContains(t, err, "app.database.datasource")
+		assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+		assert.Equal(t, int64(2048), middleware.GetMaxJSONBodyBytes())
+	})
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/clivern/tut/api"
 	"github.com/clivern/tut/db"
 	"github.com/clivern/tut/middleware"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -30,49 +32,155 @@ import (
 
 // Setup creates and configures the HTTP server
 func Setup(Static embed.FS) http.Handler {
-	r := chi.NewRouter()
+	if err := service.SetBcryptCost(viper.GetInt("app.security.bcrypt_cost")); err != nil {
+		log.Warn().Err(err).Int("default", service.DefaultBcryptCost).Msg("Invalid bcrypt cost, using the default")
+	}
+
+	trustedProxies := GetList("app.trusted_proxies")
+	if len(trustedProxies) == 0 && viper.GetBool("app.trust_proxy") {
+		trustedProxies = service.PrivateNetworks
+	}
+	if err := service.SetTrustedProxies(trustedProxies); err != nil {
+		log.Warn().Err(err).Msg("Invalid trusted proxies, forwarding headers are ignored")
+	}
+
+	middleware.SetMaxJSONBodyBytes(viper.GetInt64("app.max_json_body_bytes"))
+	service.SetStrictJSON(viper.GetBool("app.strict_json"))
 
-	r.Use(chimiddleware.Recoverer)
-	if viper.GetInt("app.timeout") > 0 {
-		timeout := time.Duration(viper.GetInt("app.timeout")) * time.Second
-		r.Use(chimiddleware.Timeout(timeout))
+	registerRateLimit := viper.GetInt("app.rate_limit.register")
+	if registerRateLimit < 1 {
+		registerRateLimit = DefaultRegisterRateLimit
 	}
+	registerRateLimiter = middleware.NewRateLimiter(registerRateLimit, time.Hour)
+
+	securityHeaders := middleware.SecurityHeadersConfig{
+		FrameOptions:          viper.GetString("app.security.frame_options"),
+		ReferrerPolicy:        viper.GetString("app.security.referrer_policy"),
+		ContentSecurityPolicy: viper.GetString("app.security.content_security_policy"),
+		PermissionsPolicy:     viper.GetString("app.security.permissions_policy"),
+		XSSProtection:         viper.GetString("app.security.xss_protection"),
+	}
+	if viper.GetBool("app.tls.status") {
+		securityHeaders.StrictTransportSecurity = viper.GetString("app.security.strict_transport_security")
+	}
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Recoverer)
+	r.Use(chimiddleware.Compress(5, "application/json"))
 	r.Use(middleware.PrometheusMiddleware)
 	r.Use(middleware.Logger)
-	r.Use(middleware.RequestSizeLimit(int64(10 * 1024 * 1024)))
+	r.Use(middleware.SecurityHeaders(securityHeaders))
+	r.Use(middleware.CORS())
+	r.Use(middleware.RequestSizeLimit())
+	r.Use(middleware.RequireSetup())
 	r.Use(middleware.SessionAuth())
 
+	adminWhitelist := middleware.IPWhitelist(GetList("app.admin.ip_whitelist"))
+	// JSON endpoints get a total deadline, transfer endpoints only an idle one
+	// so large downloads are not cut off
+	jsonTimeout := middleware.Timeout(time.Duration(viper.GetInt("app.timeout")) * time.Second)
+	transferTimeout := middleware.TransferIdleTimeout(time.Duration(viper.GetInt("app.transfer_idle_timeout")) * time.Second)
+
 	// Routes
 	r.Get("/favicon.ico", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	})
 	// Public Actions
 	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
 		r.Get("/api/v1/public/_health", api.HealthAction)
 		r.Get("/api/v1/public/_ready", api.ReadyAction)
 		r.Post("/api/v1/public/action/setup", api.SetupAction)
 		r.Get("/api/v1/public/action/setup/status", api.SetupStatusAction)
 		r.Post("/api/v1/public/action/login", api.LoginAction)
+		r.Get("/api/v1/public/action/login/options", api.LoginOptionsAction)
+		r.Get("/api/v1/public/action/oidc/login", api.OIDCLoginAction)
+		r.Get("/api/v1/public/action/oidc/callback", api.OIDCCallbackAction)
+		r.With(registerRateLimiter.Handler).Post("/api/v1/public/action/register", api.RegisterAction)
+		r.Get("/api/v1/public/action/verify-email", api.VerifyEmailAction)
 		r.Post("/api/v1/public/action/logout", api.LogoutAction)
+		r.Get("/api/v1/openapi.json", api.OpenAPIAction)
+		r.Get("/api/docs", api.APIDocsAction)
 	})
 	// Private Actions
 	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
 		r.Get("/api/v1/action/profile", api.GetProfileAction)
 		r.Put("/api/v1/action/profile", api.UpdateProfileAction)
+		r.Get("/api/v1/action/profile/sessions", api.ListProfileSessionsAction)
+		r.Get("/api/v1/users/me/settings", api.GetUserPreferencesAction)
+		r.Patch("/api/v1/users/me/settings", api.UpdateUserPreferencesAction)
+		r.Get("/api/v1/me/preferences", api.GetUIPreferencesAction)
+		r.Put("/api/v1/me/preferences", api.ReplaceUIPreferencesAction)
 	})
+	// Batch requests are replayed against the whole router
+	r.With(jsonTimeout).Post(api.BatchPath, api.BatchAction(r))
 	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
 		r.Use(middleware.RequireRole(db.UserRoleUser))
 		r.Put("/api/v1/action/settings", api.UpdateSettingsAction)
 		r.Get("/api/v1/action/settings", api.GetSettingsAction)
 	})
 	// Users routes
 	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
+		r.Use(adminWhitelist)
 		r.Use(middleware.RequireRole(db.UserRoleAdmin))
 		r.Post("/api/v1/users", api.CreateUserAction)
 		r.Get("/api/v1/users", api.ListUsersAction)
+		r.Get("/api/v1/users/inactive", api.ListInactiveUsersAction)
+		r.Post("/api/v1/users/deactivate-inactive", api.DeactivateInactiveUsersAction)
 		r.Get("/api/v1/users/{id}", api.GetUserAction)
 		r.Put("/api/v1/users/{id}", api.UpdateUserAction)
 		r.Delete("/api/v1/users/{id}", api.DeleteUserAction)
+		r.Post("/api/v1/users/{id}/activate", api.ActivateUserAction)
+		r.Get("/api/v1/users/{id}/meta", api.ListUserMetaAction)
+		r.Put("/api/v1/users/{id}/meta/{key}", api.SetUserMetaAction)
+		r.Delete("/api/v1/users/{id}/meta/{key}", api.DeleteUserMetaAction)
+	})
+	// Admin settings routes
+	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
+		r.Use(adminWhitelist)
+		r.Use(middleware.RequireRole(db.UserRoleAdmin))
+		r.Get("/api/v1/action/settings/oidc", api.GetOIDCSettingsAction)
+		r.Put("/api/v1/action/settings/oidc", api.UpdateOIDCSettingsAction)
+		r.Get("/api/v1/action/settings/registration", api.GetRegistrationSettingsAction)
+		r.Put("/api/v1/action/settings/registration", api.UpdateRegistrationSettingsAction)
+		r.Get("/api/v1/action/settings/cors", api.GetCORSSettingsAction)
+		r.Put("/api/v1/action/settings/cors", api.UpdateCORSSettingsAction)
+		r.Get("/api/v1/action/settings/history", api.ListSettingsHistoryAction)
+		r.Get("/api/v1/action/options", api.ListOptionsAction)
+	})
+	// Backup routes
+	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
+		r.Use(adminWhitelist)
+		r.Use(middleware.RequireRole(db.UserRoleAdmin))
+		r.Get("/api/v1/action/backups", api.ListBackupsAction)
+	})
+	// Transfer routes, creating a backup of a large database can outlast the JSON timeout
+	r.Group(func(r chi.Router) {
+		r.Use(transferTimeout)
+		r.Use(adminWhitelist)
+		r.Use(middleware.RequireRole(db.UserRoleAdmin))
+		r.Post("/api/v1/action/backups", api.CreateBackupAction)
+		r.Get("/api/v1/action/backups/{filename}", api.DownloadBackupAction)
+		r.Get("/api/v1/action/activities/export", api.ExportActivitiesAction)
+	})
+	// Admin routes
+	r.Group(func(r chi.Router) {
+		r.Use(jsonTimeout)
+		r.Use(adminWhitelist)
+		r.Use(middleware.RequireRole(db.UserRoleAdmin))
+		r.Get("/api/v1/action/scheduler/jobs", api.ListSchedulerJobsAction)
+		r.Get("/api/v1/action/stats", api.GetStatsAction)
+		r.Get("/api/v1/action/stats/db", api.GetDatabaseStatsAction)
+		r.Get("/api/v1/action/summary", api.GetSummaryAction)
+		r.Post("/api/v1/action/reset", api.ResetAction)
+		r.Post("/api/v1/action/reload", api.ReloadConfigAction(Reload))
 	})
 	// Metrics routes
 	r.With(middleware.BasicAuth(
@@ -126,6 +234,11 @@ func InitDatabase() error {
 		MaxIdleConns:    viper.GetInt("app.database.max_idle_conns"),
 		ConnMaxLifetime: viper.GetInt("app.database.conn_max_lifetime"),
 		DataSource:      viper.GetString("app.database.datasource"),
+
+		SlowQueryThreshold: viper.GetInt("app.database.slow_query_threshold_ms"),
+		SQLiteJournalMode:  viper.GetString("app.database.sqlite_journal_mode"),
+		SQLiteBusyTimeout:  viper.GetInt("app.database.sqlite_busy_timeout_ms"),
+		SQLiteSynchronous:  viper.GetString("app.database.sqlite_synchronous"),
 	}
 
 	return db.InitDB(dbConfig)
@@ -133,6 +246,11 @@ func InitDatabase() error {
 
 // Run starts the HTTP server with graceful shutdown support
 func Run(handler http.Handler) error {
+	tlsServer, err := NewTLSServer()
+	if err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
 	if err := InitDatabase(); err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
@@ -143,12 +261,45 @@ func Run(handler http.Handler) error {
 		}
 	}()
 
+	scheduler := module.GetScheduler()
+	if err := RegisterTasks(scheduler); err != nil {
+		return fmt.Errorf("failed to register scheduled tasks: %w", err)
+	}
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", strconv.Itoa(viper.GetInt("app.port"))),
-		Handler: handler,
+		Addr:              fmt.Sprintf(":%s", strconv.Itoa(viper.GetInt("app.port"))),
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(viper.GetInt("app.read_header_timeout")) * time.Second,
+		ReadTimeout:       time.Duration(viper.GetInt("app.read_timeout")) * time.Second,
+		WriteTimeout:      time.Duration(viper.GetInt("app.write_timeout")) * time.Second,
+		IdleTimeout:       time.Duration(viper.GetInt("app.idle_timeout")) * time.Second,
 	}
 
-	serverErrors := make(chan error, 1)
+	serverErrors := make(chan error, 2)
+
+	if tlsServer != nil {
+		srv.TLSConfig = tlsServer.Config
+
+		if tlsServer.HTTP != nil {
+			go func() {
+				log.Info().
+					Str("addr", tlsServer.HTTP.Addr).
+					Msg("Starting HTTP to HTTPS redirect server")
+
+				if err := tlsServer.HTTP.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					serverErrors <- err
+				}
+			}()
+		}
+
+		if err := tlsServer.ObtainCertificates(); err != nil {
+			tlsServer.Shutdown(context.Background())
+			return err
+		}
+	}
 
 	go func() {
 		log.Info().
@@ -157,11 +308,9 @@ func Run(handler http.Handler) error {
 			Msg("Starting HTTP server")
 
 		var err error
-		if viper.GetBool("app.tls.status") {
-			err = srv.ListenAndServeTLS(
-				viper.GetString("app.tls.crt_path"),
-				viper.GetString("app.tls.key_path"),
-			)
+		if tlsServer != nil {
+			// The certificates come from srv.TLSConfig
+			err = srv.ListenAndServeTLS("", "")
 		} else {
 			err = srv.ListenAndServe()
 		}
@@ -175,30 +324,46 @@ func Run(handler http.Handler) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	select {
-	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
-	case sig := <-quit:
-		log.Info().
-			Str("signal", sig.String()).
-			Msg("Received shutdown signal")
+	// SIGHUP reloads the configs that can change without a restart
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
 
-		shutdownTimeout := 30 * time.Second
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("server error: %w", err)
+		case <-hangup:
+			log.Info().Msg("Received SIGHUP, reloading configuration")
+			// Reload logs and records the result itself
+			Reload(nil)
+		case sig := <-quit:
+			log.Info().
+				Str("signal", sig.String()).
+				Msg("Received shutdown signal")
 
-		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-		defer cancel()
+			shutdownTimeout := 30 * time.Second
 
-		log.Info().
-			Dur("timeout", shutdownTimeout).
-			Msg("Gracefully shutting down server")
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
 
-		// Shutdown with timeout to allow in-flight requests to complete
-		if err := srv.Shutdown(ctx); err != nil {
-			return fmt.Errorf("server forced to shutdown: %w", err)
-		}
+			log.Info().
+				Dur("timeout", shutdownTimeout).
+				Msg("Gracefully shutting down server")
 
-		log.Info().Msg("Server shutdown complete")
-	}
+			// Shutdown with timeout to allow in-flight requests to complete
+			if err := srv.Shutdown(ctx); err != nil {
+				return fmt.Errorf("server forced to shutdown: %w", err)
+			}
+
+			if tlsServer != nil {
+				if err := tlsServer.Shutdown(ctx); err != nil {
+					return fmt.Errorf("redirect server forced to shutdown: %w", err)
+				}
+			}
 
-	return nil
+			log.Info().Msg("Server shutdown complete")
+			return nil
+		}
+	}
 }
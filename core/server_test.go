@@ -0,0 +1,85 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/api"
+	"github.com/clivern/tut/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnitRoutesDocumented ensures every API route has OpenAPI metadata and the other way around
+func TestUnitRoutesDocumented(t *testing.T) {
+	router, ok := Setup(embed.FS{}).(chi.Routes)
+	require.True(t, ok)
+
+	documented := map[string]bool{}
+	for _, operation := range api.OpenAPIOperations {
+		documented[operation.Method+" "+operation.Path] = true
+	}
+
+	registered := map[string]bool{}
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.HasPrefix(route, "/api/") {
+			registered[method+" "+route] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, registered)
+
+	for route := range registered {
+		assert.True(t, documented[route], "route %s has no OpenAPI metadata in api.OpenAPIOperations", route)
+	}
+	for route := range documented {
+		assert.True(t, registered[route], "OpenAPI operation %s has no registered route", route)
+	}
+}
+
+// TestUnitTransferIdleTimeoutInRouter ensures the write deadline reaches the
+// connection through every middleware of the router
+func TestUnitTransferIdleTimeoutInRouter(t *testing.T) {
+	router, ok := Setup(embed.FS{}).(chi.Router)
+	require.True(t, ok)
+
+	writeErr := make(chan error, 1)
+	router.With(middleware.TransferIdleTimeout(50*time.Millisecond)).Get("/_transfer", func(w http.ResponseWriter, _ *http.Request) {
+		// Write until the stalled client fills the socket buffers
+		chunk := make([]byte, 64*1024)
+		for i := 0; i < 16*1024; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				writeErr <- err
+				return
+			}
+		}
+		writeErr <- nil
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// The body is never read, so the transfer stalls
+	resp, err := http.Get(server.URL + "/_transfer")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	select {
+	case err := <-writeErr:
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not finish")
+	}
+}
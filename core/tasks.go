@@ -0,0 +1,42 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CleanupSessionsInterval is how often expired sessions are removed
+const CleanupSessionsInterval = 15 * time.Minute
+
+// RegisterTasks registers the built-in maintenance tasks
+func RegisterTasks(scheduler *module.Scheduler) error {
+	return scheduler.Register("CleanupSessions", CleanupSessionsInterval, CleanupSessions)
+}
+
+// CleanupSessions removes expired sessions from the database
+func CleanupSessions(_ context.Context) error {
+	sessionManager := module.NewSessionManager(
+		db.NewSessionRepository(db.GetDB()),
+		db.NewUserRepository(db.GetDB()),
+	)
+
+	deleted, err := sessionManager.CleanupExpiredSessions()
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		log.Info().Int64("deleted", deleted).Msg("Expired sessions removed")
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certificateTimeout bounds how long startup waits for an ACME certificate
+const certificateTimeout = 2 * time.Minute
+
+// TLSServer holds the TLS configuration of the main server and the optional
+// plain HTTP server that redirects to HTTPS and answers ACME challenges
+type TLSServer struct {
+	Config    *tls.Config
+	HTTP      *http.Server
+	manager   *autocert.Manager
+	hostnames []string
+}
+
+// NewTLSServer builds the TLS configuration from the app.tls configs. It
+// fails when the certificate files can not be loaded or autocert is missing
+// the hostnames or the HTTP listener it needs. It returns nil when TLS is off.
+func NewTLSServer() (*TLSServer, error) {
+	if !viper.GetBool("app.tls.status") {
+		return nil, nil
+	}
+
+	server := &TLSServer{}
+	httpPort := viper.GetInt("app.tls.http_port")
+
+	if viper.GetBool("app.tls.autocert.status") {
+		server.hostnames = GetList("app.tls.autocert.hostnames")
+		if len(server.hostnames) == 0 {
+			return nil, errors.New("autocert needs at least one hostname in app.tls.autocert.hostnames")
+		}
+		if httpPort == 0 {
+			return nil, errors.New("autocert needs app.tls.http_port to answer HTTP-01 challenges")
+		}
+
+		server.manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(server.hostnames...),
+			Cache:      autocert.DirCache(viper.GetString("app.tls.autocert.cache_dir")),
+			Email:      viper.GetString("app.tls.autocert.email"),
+		}
+		server.Config = server.manager.TLSConfig()
+	} else {
+		certificate, err := tls.LoadX509KeyPair(
+			viper.GetString("app.tls.crt_path"),
+			viper.GetString("app.tls.key_path"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		server.Config = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	}
+
+	server.Config.MinVersion = tls.VersionTLS12
+
+	if httpPort > 0 {
+		var handler http.Handler = http.HandlerFunc(redirectToHTTPS)
+		if server.manager != nil {
+			// Answers challenges and redirects every other request
+			handler = server.manager.HTTPHandler(handler)
+		}
+
+		server.HTTP = &http.Server{
+			Addr:              fmt.Sprintf(":%s", strconv.Itoa(httpPort)),
+			Handler:           handler,
+			ReadHeaderTimeout: time.Duration(viper.GetInt("app.read_header_timeout")) * time.Second,
+			ReadTimeout:       time.Duration(viper.GetInt("app.read_timeout")) * time.Second,
+			IdleTimeout:       time.Duration(viper.GetInt("app.idle_timeout")) * time.Second,
+		}
+	}
+
+	return server, nil
+}
+
+// ObtainCertificates requests the autocert certificates so an unreachable
+// ACME server fails the startup instead of the first TLS handshake. The
+// HTTP server must already be listening to answer the challenges.
+func (s *TLSServer) ObtainCertificates() error {
+	if s.manager == nil {
+		return nil
+	}
+
+	for _, hostname := range s.hostnames {
+		log.Info().Str("hostname", hostname).Msg("Obtaining TLS certificate")
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := s.manager.GetCertificate(&tls.ClientHelloInfo{ServerName: hostname})
+			errs <- err
+		}()
+
+		select {
+		case err := <-errs:
+			if err != nil {
+				return fmt.Errorf("failed to obtain TLS certificate for %s: %w", hostname, err)
+			}
+		case <-time.After(certificateTimeout):
+			return fmt.Errorf("timed out obtaining TLS certificate for %s", hostname)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown gracefully stops the HTTP server
+func (s *TLSServer) Shutdown(ctx context.Context) error {
+	if s.HTTP == nil {
+		return nil
+	}
+	return s.HTTP.Shutdown(ctx)
+}
+
+// redirectToHTTPS redirects a plain HTTP request to the HTTPS port
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if port := viper.GetInt("app.port"); port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
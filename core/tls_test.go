@@ -0,0 +1,142 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self signed certificate and key to dir
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	crtPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	require.NoError(t, os.WriteFile(crtPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return crtPath, keyPath
+}
+
+// TestUnitNewTLSServer tests building the TLS configuration
+func TestUnitNewTLSServer(t *testing.T) {
+	keys := []string{
+		"app.tls.status",
+		"app.tls.crt_path",
+		"app.tls.key_path",
+		"app.tls.http_port",
+		"app.tls.autocert.status",
+		"app.tls.autocert.hostnames",
+		"app.tls.autocert.cache_dir",
+		"app.port",
+	}
+	reset := func() {
+		for _, key := range keys {
+			viper.Set(key, nil)
+		}
+	}
+	defer reset()
+
+	t.Run("TLS off", func(t *testing.T) {
+		reset()
+
+		server, err := NewTLSServer()
+		require.NoError(t, err)
+		assert.Nil(t, server)
+	})
+
+	t.Run("Missing certificate files fail", func(t *testing.T) {
+		reset()
+		viper.Set("app.tls.status", true)
+		viper.Set("app.tls.crt_path", "/nonexistent/server.crt")
+		viper.Set("app.tls.key_path", "/nonexistent/server.key")
+
+		_, err := NewTLSServer()
+		assert.ErrorContains(t, err, "failed to load TLS certificate")
+	})
+
+	t.Run("Certificate files", func(t *testing.T) {
+		reset()
+		crtPath, keyPath := writeTestCertificate(t, t.TempDir())
+		viper.Set("app.tls.status", true)
+		viper.Set("app.tls.crt_path", crtPath)
+		viper.Set("app.tls.key_path", keyPath)
+
+		server, err := NewTLSServer()
+		require.NoError(t, err)
+		assert.Len(t, server.Config.Certificates, 1)
+		assert.Nil(t, server.HTTP)
+		assert.NoError(t, server.ObtainCertificates())
+	})
+
+	t.Run("Autocert needs hostnames and the HTTP listener", func(t *testing.T) {
+		reset()
+		viper.Set("app.tls.status", true)
+		viper.Set("app.tls.autocert.status", true)
+
+		_, err := NewTLSServer()
+		assert.ErrorContains(t, err, "hostname")
+
+		viper.Set("app.tls.autocert.hostnames", "tut.example.com")
+		_, err = NewTLSServer()
+		assert.ErrorContains(t, err, "http_port")
+
+		viper.Set("app.tls.http_port", 80)
+		viper.Set("app.tls.autocert.cache_dir", t.TempDir())
+		server, err := NewTLSServer()
+		require.NoError(t, err)
+		assert.NotNil(t, server.Config.GetCertificate)
+		assert.Equal(t, ":80", server.HTTP.Addr)
+	})
+
+	t.Run("HTTP requests are redirected to HTTPS", func(t *testing.T) {
+		reset()
+		crtPath, keyPath := writeTestCertificate(t, t.TempDir())
+		viper.Set("app.tls.status", true)
+		viper.Set("app.tls.crt_path", crtPath)
+		viper.Set("app.tls.key_path", keyPath)
+		viper.Set("app.tls.http_port", 8080)
+		viper.Set("app.port", 8443)
+
+		server, err := NewTLSServer()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		server.HTTP.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://tut.example.com:8080/api/docs?x=1", nil))
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "https://tut.example.com:8443/api/docs?x=1", w.Header().Get("Location"))
+	})
+}
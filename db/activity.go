@@ -24,14 +24,30 @@ type Activity struct {
 	CreatedAt  time.Time
 }
 
+// Activity actions counted by Summarize.
+const (
+	ActivityActionFileUploaded   = "file.uploaded"
+	ActivityActionFileDownloaded = "file.downloaded"
+	ActivityActionFileDeleted    = "file.deleted"
+)
+
+// ActivitySummary holds aggregated activity metrics for a period.
+type ActivitySummary struct {
+	TotalUploads      int64
+	TotalDownloads    int64
+	TotalDeletes      int64
+	NewUsers          int64
+	UniqueActiveUsers int64
+}
+
 // ActivityRepository handles database operations for activity logs.
 type ActivityRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewActivityRepository creates a new activity repository.
-func NewActivityRepository(db *sql.DB) *ActivityRepository {
-	return &ActivityRepository{db: db}
+func NewActivityRepository(db Querier) *ActivityRepository {
+	return &ActivityRepository{db: withBusyRetry(db)}
 }
 
 // Create inserts a new activity log entry into the database.
@@ -136,7 +152,7 @@ func (r *ActivityRepository) ListByAction(action string, limit, offset int) ([]*
 			id, user_id, user_email, action, entity_type, entity_id, details, ip_address, user_agent, created_at
 		FROM activities
 		WHERE action = ?
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT ? OFFSET ?`,
 		action,
 		limit,
@@ -194,6 +210,49 @@ func (r *ActivityRepository) ListByDateRange(startDate, endDate time.Time, limit
 	return r.scanActivities(rows)
 }
 
+// EachByDateRange calls fn for every activity log within [from, to) with an ID
+// greater than afterID, in ID order. Rows are read one at a time so large
+// ranges are never loaded into memory.
+func (r *ActivityRepository) EachByDateRange(from, to time.Time, afterID int64, fn func(*Activity) error) error {
+	rows, err := r.db.Query(
+		`SELECT
+			id, user_id, user_email, action, entity_type, entity_id, details, ip_address, user_agent, created_at
+		FROM activities
+		WHERE created_at >= ? AND created_at < ? AND id > ?
+		ORDER BY id ASC`,
+		from,
+		to,
+		afterID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		activity := &Activity{}
+		if err := rows.Scan(
+			&activity.ID,
+			&activity.UserID,
+			&activity.UserEmail,
+			&activity.Action,
+			&activity.EntityType,
+			&activity.EntityID,
+			&activity.Details,
+			&activity.IPAddress,
+			&activity.UserAgent,
+			&activity.CreatedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(activity); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 // Count returns the total number of activity logs.
 func (r *ActivityRepository) Count() (int64, error) {
 	var count int64
@@ -208,6 +267,40 @@ func (r *ActivityRepository) CountByUser(userID int64) (int64, error) {
 	return count, err
 }
 
+// Summarize aggregates the activity logs and new users created within
+// [from, to) in a single query.
+func (r *ActivityRepository) Summarize(from, to time.Time) (*ActivitySummary, error) {
+	summary := &ActivitySummary{}
+	err := r.db.QueryRow(
+		`SELECT
+			COALESCE(SUM(CASE WHEN action = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN action = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN action = ? THEN 1 ELSE 0 END), 0),
+			(SELECT COUNT(*) FROM users WHERE created_at >= ? AND created_at < ?),
+			COUNT(DISTINCT user_id)
+		FROM activities
+		WHERE created_at >= ? AND created_at < ?`,
+		ActivityActionFileUploaded,
+		ActivityActionFileDownloaded,
+		ActivityActionFileDeleted,
+		from,
+		to,
+		from,
+		to,
+	).Scan(
+		&summary.TotalUploads,
+		&summary.TotalDownloads,
+		&summary.TotalDeletes,
+		&summary.NewUsers,
+		&summary.UniqueActiveUsers,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
 // DeleteOlderThan removes activity logs older than a specific date (for cleanup).
 func (r *ActivityRepository) DeleteOlderThan(date time.Time) (int64, error) {
 	result, err := r.db.Exec("DELETE FROM activities WHERE created_at < ?", date)
@@ -217,6 +310,12 @@ func (r *ActivityRepository) DeleteOlderThan(date time.Time) (int64, error) {
 	return result.RowsAffected()
 }
 
+// DeleteAll removes all activity logs.
+func (r *ActivityRepository) DeleteAll() error {
+	_, err := r.db.Exec("DELETE FROM activities")
+	return err
+}
+
 func (r *ActivityRepository) scanActivities(rows *sql.Rows) ([]*Activity, error) {
 	var activities []*Activity
 	for rows.Next() {
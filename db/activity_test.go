@@ -0,0 +1,151 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupActivityTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL DEFAULT 'user',
+			api_key VARCHAR(255) UNIQUE,
+			is_active BOOLEAN DEFAULT 1,
+			last_login_at DATETIME NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		CREATE TABLE activities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			user_email VARCHAR(255),
+			action VARCHAR(100) NOT NULL,
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id INTEGER,
+			details TEXT,
+			ip_address VARCHAR(45),
+			user_agent VARCHAR(500),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestUnitActivityRepository_Summarize(t *testing.T) {
+	t.Run("Empty period", func(t *testing.T) {
+		db := setupActivityTestDB(t)
+		defer db.Close()
+
+		repo := NewActivityRepository(db)
+		summary, err := repo.Summarize(time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, &ActivitySummary{}, summary)
+	})
+
+	t.Run("Counts activities and users within the period", func(t *testing.T) {
+		db := setupActivityTestDB(t)
+		defer db.Close()
+
+		_, err := db.Exec(`INSERT INTO users (email, password, role, created_at) VALUES
+			('old@example.com', 'x', 'user', '2025-01-01 10:00:00'),
+			('new1@example.com', 'x', 'user', '2025-02-01 10:00:00'),
+			('new2@example.com', 'x', 'user', '2025-02-02 10:00:00')`)
+		require.NoError(t, err)
+
+		_, err = db.Exec(`INSERT INTO activities (user_id, action, entity_type, created_at) VALUES
+			(1, 'file.uploaded', 'file', '2025-01-15 10:00:00'),
+			(1, 'file.uploaded', 'file', '2025-02-01 11:00:00'),
+			(2, 'file.uploaded', 'file', '2025-02-01 12:00:00'),
+			(2, 'file.downloaded', 'file', '2025-02-02 12:00:00'),
+			(3, 'file.deleted', 'file', '2025-02-03 12:00:00'),
+			(3, 'user.deactivate', 'user', '2025-02-03 13:00:00'),
+			(NULL, 'file.downloaded', 'file', '2025-02-04 13:00:00'),
+			(1, 'file.deleted', 'file', '2025-03-01 10:00:00')`)
+		require.NoError(t, err)
+
+		repo := NewActivityRepository(db)
+		summary, err := repo.Summarize(
+			time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, &ActivitySummary{
+			TotalUploads:      2,
+			TotalDownloads:    2,
+			TotalDeletes:      1,
+			NewUsers:          2,
+			UniqueActiveUsers: 3,
+		}, summary)
+	})
+}
+
+func TestUnitActivityRepository_EachByDateRange(t *testing.T) {
+	db := setupActivityTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`INSERT INTO activities (user_id, action, entity_type, created_at) VALUES
+		(1, 'file.uploaded', 'file', '2025-01-15 10:00:00'),
+		(1, 'file.uploaded', 'file', '2025-02-01 11:00:00'),
+		(2, 'file.downloaded', 'file', '2025-02-02 12:00:00'),
+		(3, 'file.deleted', 'file', '2025-02-03 12:00:00'),
+		(1, 'file.deleted', 'file', '2025-03-01 10:00:00')`)
+	require.NoError(t, err)
+
+	repo := NewActivityRepository(db)
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Iterates the range in ID order", func(t *testing.T) {
+		var ids []int64
+		err := repo.EachByDateRange(from, to, 0, func(activity *Activity) error {
+			ids = append(ids, activity.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3, 4}, ids)
+	})
+
+	t.Run("Resumes after the cursor", func(t *testing.T) {
+		var ids []int64
+		err := repo.EachByDateRange(from, to, 2, func(activity *Activity) error {
+			ids = append(ids, activity.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 4}, ids)
+	})
+
+	t.Run("Stops on callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.EachByDateRange(from, to, 0, func(_ *Activity) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -7,6 +7,9 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"           // PostgreSQL driver
@@ -32,8 +35,21 @@ type Config struct {
 	MaxIdleConns    int
 	ConnMaxLifetime int
 	DataSource      string
+	// SlowQueryThreshold in milliseconds, queries slower than this are logged (0 disables tracing)
+	SlowQueryThreshold int
+	// SQLite journal mode, busy timeout in milliseconds and synchronous setting
+	SQLiteJournalMode string
+	SQLiteBusyTimeout int
+	SQLiteSynchronous string
 }
 
+// SQLite defaults used when the config leaves them empty
+const (
+	DefaultSQLiteJournalMode = "WAL"
+	DefaultSQLiteBusyTimeout = 5000
+	DefaultSQLiteSynchronous = "NORMAL"
+)
+
 // NewConnection creates a new database connection based on the driver
 func NewConnection(config Config) (*Connection, error) {
 	var dsn string
@@ -50,13 +66,13 @@ func NewConnection(config Config) (*Connection, error) {
 			config.Password,
 			config.Database,
 		)
-		db, err = sql.Open("postgres", dsn)
+		db, err = open("postgres", dsn, config.SlowQueryThreshold)
 	case "sqlite":
 		dsn = config.DataSource
 		if dsn == "" {
 			dsn = config.Database
 		}
-		db, err = sql.Open("sqlite3", dsn)
+		db, err = open("sqlite3", sqliteDSN(dsn, config), config.SlowQueryThreshold)
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s (supported: postgres, postgresql, sqlite)", config.Driver)
 	}
@@ -92,6 +108,45 @@ func NewConnection(config Config) (*Connection, error) {
 	}, nil
 }
 
+// sqliteDSN adds the journal mode, busy timeout and synchronous pragmas to the
+// SQLite data source. Transactions take the write lock on BEGIN so concurrent
+// writers wait on the busy timeout instead of failing when upgrading a read lock.
+func sqliteDSN(dsn string, config Config) string {
+	journalMode := config.SQLiteJournalMode
+	if journalMode == "" {
+		journalMode = DefaultSQLiteJournalMode
+	}
+	busyTimeout := config.SQLiteBusyTimeout
+	if busyTimeout <= 0 {
+		busyTimeout = DefaultSQLiteBusyTimeout
+	}
+	synchronous := config.SQLiteSynchronous
+	if synchronous == "" {
+		synchronous = DefaultSQLiteSynchronous
+	}
+
+	params := url.Values{}
+	params.Set("_journal_mode", journalMode)
+	params.Set("_busy_timeout", strconv.Itoa(busyTimeout))
+	params.Set("_synchronous", synchronous)
+	params.Set("_txlock", "immediate")
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + params.Encode()
+}
+
+// open opens a database handle, wrapped with query tracing if a threshold is set
+func open(driverName, dsn string, slowQueryThreshold int) (*sql.DB, error) {
+	if slowQueryThreshold > 0 {
+		return NewTracingDB(driverName, dsn, time.Duration(slowQueryThreshold)*time.Millisecond)
+	}
+	return sql.Open(driverName, dsn)
+}
+
 // Close closes the database connection
 func (c *Connection) Close() error {
 	if c.DB != nil {
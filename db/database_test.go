@@ -6,9 +6,11 @@ package db
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUnitSQLiteConnection(t *testing.T) {
@@ -29,6 +31,37 @@ func TestUnitSQLiteConnection(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUnitSQLiteDSN(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		dsn := sqliteDSN("/tmp/tut.db", Config{})
+		assert.Equal(t, "/tmp/tut.db?_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL&_txlock=immediate", dsn)
+	})
+
+	t.Run("Configured values and existing parameters", func(t *testing.T) {
+		dsn := sqliteDSN("file:/tmp/tut.db?cache=shared", Config{
+			SQLiteJournalMode: "DELETE",
+			SQLiteBusyTimeout: 1000,
+			SQLiteSynchronous: "FULL",
+		})
+		assert.Equal(t, "file:/tmp/tut.db?cache=shared&_busy_timeout=1000&_journal_mode=DELETE&_synchronous=FULL&_txlock=immediate", dsn)
+	})
+}
+
+func TestUnitSQLiteWALMode(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "tut.db")
+
+	conn, err := NewConnection(Config{
+		Driver:     "sqlite",
+		DataSource: tmpFile,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var journalMode string
+	require.NoError(t, conn.DB.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+}
+
 func TestUnitUnsupportedDriver(t *testing.T) {
 	config := Config{
 		Driver: "mysql",
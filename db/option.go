@@ -20,12 +20,12 @@ type Option struct {
 
 // OptionRepository handles database operations for options.
 type OptionRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewOptionRepository creates a new option repository.
-func NewOptionRepository(db *sql.DB) *OptionRepository {
-	return &OptionRepository{db: db}
+func NewOptionRepository(db Querier) *OptionRepository {
+	return &OptionRepository{db: withBusyRetry(db)}
 }
 
 // Create inserts a new option into the database.
@@ -76,12 +76,47 @@ func (r *OptionRepository) Update(key, value string) error {
 	return err
 }
 
+// GetOrDefault retrieves an option value by key or the default value when
+// the option does not exist.
+func (r *OptionRepository) GetOrDefault(key, defaultValue string) (string, error) {
+	option, err := r.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if option == nil {
+		return defaultValue, nil
+	}
+	return option.Value, nil
+}
+
+// Upsert inserts an option or updates its value if the key already exists.
+func (r *OptionRepository) Upsert(key, value string) error {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(
+		`INSERT INTO options (key, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = excluded.value, updated_at = excluded.updated_at`,
+		key,
+		value,
+		now,
+		now,
+	)
+	return err
+}
+
 // Delete removes an option from the database.
 func (r *OptionRepository) Delete(key string) error {
 	_, err := r.db.Exec("DELETE FROM options WHERE key = ?", key)
 	return err
 }
 
+// DeleteAll removes all options from the database.
+func (r *OptionRepository) DeleteAll() error {
+	_, err := r.db.Exec("DELETE FROM options")
+	return err
+}
+
 // List retrieves all options from the database.
 func (r *OptionRepository) List() ([]*Option, error) {
 	rows, err := r.db.Query("SELECT id, key, value, created_at, updated_at FROM options ORDER BY key")
@@ -200,6 +200,66 @@ func TestUnitOptionRepository_Update(t *testing.T) {
 	})
 }
 
+func TestUnitOptionRepository_Upsert(t *testing.T) {
+	conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewOptionRepository(conn.DB)
+
+	t.Run("Upsert creates missing option", func(t *testing.T) {
+		err := repo.Upsert("oidc_enabled", "1")
+		assert.NoError(t, err)
+
+		opt, err := repo.Get("oidc_enabled")
+		assert.NoError(t, err)
+		assert.NotNil(t, opt)
+		assert.Equal(t, "1", opt.Value)
+	})
+
+	t.Run("Upsert updates existing option", func(t *testing.T) {
+		err := repo.Create("oidc_client_id", "initial")
+		require.NoError(t, err)
+
+		err = repo.Upsert("oidc_client_id", "updated")
+		assert.NoError(t, err)
+
+		opt, err := repo.Get("oidc_client_id")
+		assert.NoError(t, err)
+		assert.Equal(t, "updated", opt.Value)
+	})
+}
+
+func TestUnitOptionRepository_GetOrDefault(t *testing.T) {
+	conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewOptionRepository(conn.DB)
+
+	t.Run("GetOrDefault returns default for missing option", func(t *testing.T) {
+		value, err := repo.GetOrDefault("missing_key", "fallback")
+		assert.NoError(t, err)
+		assert.Equal(t, "fallback", value)
+	})
+
+	t.Run("GetOrDefault returns stored value", func(t *testing.T) {
+		err := repo.Create("app_name", "Tut")
+		require.NoError(t, err)
+
+		value, err := repo.GetOrDefault("app_name", "fallback")
+		assert.NoError(t, err)
+		assert.Equal(t, "Tut", value)
+	})
+
+	t.Run("GetOrDefault keeps empty stored value", func(t *testing.T) {
+		err := repo.Create("app_email", "")
+		require.NoError(t, err)
+
+		value, err := repo.GetOrDefault("app_email", "fallback")
+		assert.NoError(t, err)
+		assert.Equal(t, "", value)
+	})
+}
+
 func TestUnitOptionRepository_Delete(t *testing.T) {
 	conn, cleanup := setupTestDB(t)
 	defer cleanup()
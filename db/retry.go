@@ -0,0 +1,79 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+// Retry settings for writes that fail because SQLite is busy
+const (
+	busyRetryAttempts = 5
+	busyRetryDelay    = 20 * time.Millisecond
+)
+
+// IsBusyError reports whether err is a SQLite busy or locked error
+func IsBusyError(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// RetryOnBusy runs fn and retries it with exponential backoff while it
+// fails with a SQLite busy error. Other errors are returned immediately.
+func RetryOnBusy(ctx context.Context, fn func() error) error {
+	delay := busyRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusyError(err) || attempt == busyRetryAttempts {
+			return err
+		}
+
+		log.Warn().Err(err).Int("attempt", attempt).Msg("Database is busy, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// busyRetryDB retries the statements repositories run outside of a
+// transaction while SQLite reports the database as busy
+type busyRetryDB struct {
+	*sql.DB
+}
+
+// Exec runs a statement and retries it while the database is busy
+func (b busyRetryDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := RetryOnBusy(context.Background(), func() error {
+		var err error
+		result, err = b.DB.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
+
+// withBusyRetry retries single statement writes on a database handle.
+// Statements in a transaction are left alone, WithTx retries the whole
+// transaction instead.
+func withBusyRetry(q Querier) Querier {
+	if database, ok := q.(*sql.DB); ok {
+		return busyRetryDB{DB: database}
+	}
+	return q
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitIsBusyError(t *testing.T) {
+	assert.True(t, IsBusyError(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.True(t, IsBusyError(fmt.Errorf("failed: %w", sqlite3.Error{Code: sqlite3.ErrLocked})))
+	assert.False(t, IsBusyError(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+	assert.False(t, IsBusyError(errors.New("database is locked")))
+	assert.False(t, IsBusyError(nil))
+}
+
+func TestUnitRetryOnBusy(t *testing.T) {
+	t.Run("Retries until the write succeeds", func(t *testing.T) {
+		calls := 0
+		err := RetryOnBusy(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return sqlite3.Error{Code: sqlite3.ErrBusy}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		calls := 0
+		err := RetryOnBusy(context.Background(), func() error {
+			calls++
+			return sqlite3.Error{Code: sqlite3.ErrBusy}
+		})
+		assert.True(t, IsBusyError(err))
+		assert.Equal(t, busyRetryAttempts, calls)
+	})
+
+	t.Run("Does not retry other errors", func(t *testing.T) {
+		expected := errors.New("failed")
+		calls := 0
+		err := RetryOnBusy(context.Background(), func() error {
+			calls++
+			return expected
+		})
+		assert.ErrorIs(t, err, expected)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestUnitRepositoryRetriesBusyWrites(t *testing.T) {
+	conn, err := NewConnection(Config{
+		Driver:            "sqlite",
+		DataSource:        filepath.Join(t.TempDir(), "tut.db"),
+		SQLiteBusyTimeout: 1,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.DB.Exec(`
+		CREATE TABLE options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL UNIQUE,
+			value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	// Hold the write lock on another connection
+	ctx := context.Background()
+	locker, err := conn.DB.Conn(ctx)
+	require.NoError(t, err)
+	defer locker.Close()
+	_, err = locker.ExecContext(ctx, "BEGIN IMMEDIATE")
+	require.NoError(t, err)
+
+	_, err = conn.DB.Exec("INSERT INTO options (key, value) VALUES ('direct', 'value')")
+	require.True(t, IsBusyError(err))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		locker.ExecContext(ctx, "COMMIT")
+	}()
+
+	require.NoError(t, NewOptionRepository(conn.DB).Create("retried", "value"))
+
+	option, err := NewOptionRepository(conn.DB).Get("retried")
+	require.NoError(t, err)
+	assert.NotNil(t, option)
+}
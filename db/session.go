@@ -23,12 +23,12 @@ type Session struct {
 
 // SessionRepository handles database operations for sessions.
 type SessionRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewSessionRepository creates a new session repository.
-func NewSessionRepository(db *sql.DB) *SessionRepository {
-	return &SessionRepository{db: db}
+func NewSessionRepository(db Querier) *SessionRepository {
+	return &SessionRepository{db: withBusyRetry(db)}
 }
 
 // Create inserts a new session into the database.
@@ -122,6 +122,29 @@ func (r *SessionRepository) GetByUserID(userID int64) ([]*Session, error) {
 	}
 	defer rows.Close()
 
+	return r.scanSessions(rows)
+}
+
+// GetActiveByUserID retrieves the non-expired sessions for a user.
+func (r *SessionRepository) GetActiveByUserID(userID int64) ([]*Session, error) {
+	rows, err := r.db.Query(
+		`SELECT id, token, user_id, ip_address, user_agent, expires_at, created_at, updated_at
+		FROM sessions
+		WHERE user_id = ? AND expires_at > ?
+		ORDER BY created_at DESC`,
+		userID,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanSessions(rows)
+}
+
+// scanSessions scans session rows into a slice.
+func (r *SessionRepository) scanSessions(rows *sql.Rows) ([]*Session, error) {
 	var sessions []*Session
 	for rows.Next() {
 		session := &Session{}
@@ -161,6 +184,12 @@ func (r *SessionRepository) DeleteByUserID(userID int64) error {
 	return err
 }
 
+// DeleteAll removes all sessions.
+func (r *SessionRepository) DeleteAll() error {
+	_, err := r.db.Exec("DELETE FROM sessions")
+	return err
+}
+
 // DeleteExpired removes all expired sessions.
 func (r *SessionRepository) DeleteExpired() (int64, error) {
 	result, err := r.db.Exec("DELETE FROM sessions WHERE expires_at < ?", time.Now().UTC())
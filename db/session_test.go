@@ -254,6 +254,42 @@ func TestUnitSessionRepository_GetByUserID(t *testing.T) {
 	})
 }
 
+func TestUnitSessionRepository_GetActiveByUserID(t *testing.T) {
+	db := setupSessionTestDB(t)
+	defer db.Close()
+
+	userRepo := NewUserRepository(db)
+	sessionRepo := NewSessionRepository(db)
+
+	user := &User{
+		Email:    "test@example.com",
+		Password: "hashedpassword",
+		Role:     "user",
+		IsActive: true,
+	}
+	err := userRepo.Create(user)
+	assert.NoError(t, err)
+
+	active := &Session{
+		Token:     "active",
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(24 * time.Hour),
+	}
+	assert.NoError(t, sessionRepo.Create(active))
+
+	expired := &Session{
+		Token:     "expired",
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(-1 * time.Hour),
+	}
+	assert.NoError(t, sessionRepo.Create(expired))
+
+	sessions, err := sessionRepo.GetActiveByUserID(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "active", sessions[0].Token)
+}
+
 func TestUnitSessionRepository_Delete(t *testing.T) {
 	t.Run("Delete existing session", func(t *testing.T) {
 		db := setupSessionTestDB(t)
@@ -0,0 +1,203 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var slowQueriesTotal = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "tut_slow_queries_total",
+		Help: "Total number of database queries slower than the configured threshold",
+	},
+)
+
+// TracingConnector wraps a database driver and reports queries
+// that take longer than the configured threshold
+type TracingConnector struct {
+	driver    driver.Driver
+	dsn       string
+	threshold time.Duration
+}
+
+// NewTracingDB opens a database handle whose queries are traced for slowness.
+// Repositories keep working with *sql.DB since tracing happens at the driver level.
+func NewTracingDB(driverName, dsn string, threshold time.Duration) (*sql.DB, error) {
+	// sql.Open does not connect, it is only used to look up the registered driver
+	base, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := base.Driver()
+	base.Close()
+
+	return sql.OpenDB(&TracingConnector{
+		driver:    drv,
+		dsn:       dsn,
+		threshold: threshold,
+	}), nil
+}
+
+// Connect opens a new traced connection
+func (t *TracingConnector) Connect(_ context.Context) (driver.Conn, error) {
+	conn, err := t.driver.Open(t.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &tracingConn{Conn: conn, threshold: t.threshold}, nil
+}
+
+// Driver returns the underlying driver
+func (t *TracingConnector) Driver() driver.Driver {
+	return t.driver
+}
+
+// observe logs and counts the query if it exceeded the threshold
+func observe(threshold time.Duration, query string, start time.Time) {
+	duration := time.Since(start)
+	if duration < threshold {
+		return
+	}
+
+	slowQueriesTotal.Inc()
+
+	log.Warn().
+		Str("query", query).
+		Dur("duration", duration).
+		Msg("Slow database query")
+}
+
+// tracingConn wraps a driver connection to time queries
+type tracingConn struct {
+	driver.Conn
+	threshold time.Duration
+}
+
+func (c *tracingConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &tracingStmt{Stmt: stmt, query: query, threshold: c.threshold}, nil
+}
+
+func (c *tracingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracingStmt{Stmt: stmt, query: query, threshold: c.threshold}, nil
+}
+
+func (c *tracingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	defer observe(c.threshold, query, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *tracingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	defer observe(c.threshold, query, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *tracingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tracingStmt wraps a prepared statement to time its execution
+type tracingStmt struct {
+	driver.Stmt
+	query     string
+	threshold time.Duration
+}
+
+func (s *tracingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer observe(s.threshold, s.query, time.Now())
+
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *tracingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer observe(s.threshold, s.query, time.Now())
+
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+// namedValuesToValues converts named arguments for drivers without context support
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("named arguments are not supported by this driver")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitTracingDB(t *testing.T) {
+	t.Run("Slow queries are counted", func(t *testing.T) {
+		tracingDB, err := NewTracingDB("sqlite3", ":memory:", time.Nanosecond)
+		require.NoError(t, err)
+		defer tracingDB.Close()
+		tracingDB.SetMaxOpenConns(1)
+
+		before := testutil.ToFloat64(slowQueriesTotal)
+
+		_, err = tracingDB.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+		assert.NoError(t, err)
+		_, err = tracingDB.Exec("INSERT INTO items (name) VALUES (?)", "first")
+		assert.NoError(t, err)
+
+		var name string
+		err = tracingDB.QueryRow("SELECT name FROM items WHERE id = ?", 1).Scan(&name)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", name)
+
+		assert.Equal(t, before+3, testutil.ToFloat64(slowQueriesTotal))
+	})
+
+	t.Run("Fast queries are not counted", func(t *testing.T) {
+		tracingDB, err := NewTracingDB("sqlite3", ":memory:", time.Hour)
+		require.NoError(t, err)
+		defer tracingDB.Close()
+		tracingDB.SetMaxOpenConns(1)
+
+		before := testutil.ToFloat64(slowQueriesTotal)
+
+		_, err = tracingDB.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+		assert.NoError(t, err)
+
+		stmt, err := tracingDB.Prepare("INSERT INTO items (name) VALUES (?)")
+		require.NoError(t, err)
+		_, err = stmt.Exec("second")
+		assert.NoError(t, err)
+		stmt.Close()
+
+		assert.Equal(t, before, testutil.ToFloat64(slowQueriesTotal))
+	})
+
+	t.Run("Transactions work through the wrapper", func(t *testing.T) {
+		tracingDB, err := NewTracingDB("sqlite3", ":memory:", time.Hour)
+		require.NoError(t, err)
+		defer tracingDB.Close()
+		tracingDB.SetMaxOpenConns(1)
+
+		_, err = tracingDB.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+		require.NoError(t, err)
+
+		tx, err := tracingDB.Begin()
+		require.NoError(t, err)
+		_, err = tx.Exec("INSERT INTO items (name) VALUES (?)", "third")
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Rollback())
+
+		var count int
+		assert.NoError(t, tracingDB.QueryRow("SELECT COUNT(*) FROM items").Scan(&count))
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestUnitSQLiteConnectionWithTracing(t *testing.T) {
+	conn, err := NewConnection(Config{
+		Driver:             "sqlite",
+		DataSource:         ":memory:",
+		SlowQueryThreshold: 100,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, conn.Ping())
+}
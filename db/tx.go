@@ -0,0 +1,75 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Querier is implemented by both *sql.DB and *sql.Tx so repositories
+// can run inside or outside of a transaction.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Repos groups the repositories bound to the same database handle.
+type Repos struct {
+	Users      *UserRepository
+	UsersMeta  *UserMetaRepository
+	Options    *OptionRepository
+	Sessions   *SessionRepository
+	Activities *ActivityRepository
+}
+
+// NewRepos creates the repositories bound to the given database handle.
+func NewRepos(q Querier) *Repos {
+	return &Repos{
+		Users:      NewUserRepository(q),
+		UsersMeta:  NewUserMetaRepository(q),
+		Options:    NewOptionRepository(q),
+		Sessions:   NewSessionRepository(q),
+		Activities: NewActivityRepository(q),
+	}
+}
+
+// WithTx runs fn with repositories bound to a new transaction. The
+// transaction is committed when fn returns nil and rolled back when it
+// returns an error or panics. If SQLite reports the database as busy the
+// whole transaction is retried, so fn may run more than once.
+func WithTx(ctx context.Context, database *sql.DB, fn func(tx *Repos) error) error {
+	return RetryOnBusy(ctx, func() error {
+		return runTx(ctx, database, fn)
+	})
+}
+
+// runTx runs fn inside a single transaction
+func runTx(ctx context.Context, database *sql.DB, fn func(tx *Repos) error) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(NewRepos(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitWithTx(t *testing.T) {
+	conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewOptionRepository(conn.DB)
+
+	t.Run("Commits when the function succeeds", func(t *testing.T) {
+		err := WithTx(context.Background(), conn.DB, func(tx *Repos) error {
+			if err := tx.Options.Create("app_name", "Tut"); err != nil {
+				return err
+			}
+			return tx.Options.Create("app_email", "admin@example.com")
+		})
+		require.NoError(t, err)
+
+		value, err := repo.GetOrDefault("app_email", "")
+		require.NoError(t, err)
+		assert.Equal(t, "admin@example.com", value)
+	})
+
+	t.Run("Rolls back when the function fails", func(t *testing.T) {
+		expected := errors.New("failed")
+
+		err := WithTx(context.Background(), conn.DB, func(tx *Repos) error {
+			if err := tx.Options.Create("smtp_server", "smtp.example.com"); err != nil {
+				return err
+			}
+			return expected
+		})
+		assert.ErrorIs(t, err, expected)
+
+		option, err := repo.Get("smtp_server")
+		require.NoError(t, err)
+		assert.Nil(t, option)
+	})
+
+	t.Run("Rolls back and re-panics when the function panics", func(t *testing.T) {
+		assert.PanicsWithValue(t, "boom", func() {
+			WithTx(context.Background(), conn.DB, func(tx *Repos) error {
+				if err := tx.Options.Create("smtp_port", "587"); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+
+		option, err := repo.Get("smtp_port")
+		require.NoError(t, err)
+		assert.Nil(t, option)
+	})
+}
+
+func TestUnitWithTxConcurrentWrites(t *testing.T) {
+	conn, err := NewConnection(Config{
+		Driver:       "sqlite",
+		DataSource:   filepath.Join(t.TempDir(), "tut.db"),
+		MaxOpenConns: 25,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.DB.Exec(`
+		CREATE TABLE options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL UNIQUE,
+			value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- WithTx(context.Background(), conn.DB, func(tx *Repos) error {
+				if err := tx.Options.Create(fmt.Sprintf("key_%d", i), "value"); err != nil {
+					return err
+				}
+				return tx.Options.Upsert("counter", fmt.Sprintf("%d", i))
+			})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	options, err := NewOptionRepository(conn.DB).List()
+	require.NoError(t, err)
+	assert.Len(t, options, 51)
+}
@@ -6,6 +6,10 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -31,12 +35,12 @@ type User struct {
 
 // UserRepository handles database operations for users.
 type UserRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewUserRepository creates a new user repository.
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db Querier) *UserRepository {
+	return &UserRepository{db: withBusyRetry(db)}
 }
 
 // Create inserts a new user into the database.
@@ -188,6 +192,12 @@ func (r *UserRepository) Delete(id int64) error {
 	return err
 }
 
+// DeleteAll removes all users from the database.
+func (r *UserRepository) DeleteAll() error {
+	_, err := r.db.Exec("DELETE FROM users")
+	return err
+}
+
 // List retrieves all users with pagination.
 func (r *UserRepository) List(limit, offset int) ([]*User, error) {
 	rows, err := r.db.Query(
@@ -203,6 +213,52 @@ func (r *UserRepository) List(limit, offset int) ([]*User, error) {
 	}
 	defer rows.Close()
 
+	return r.scanUsers(rows)
+}
+
+// ListInactive retrieves active users that did not login within the olderThan
+// duration, including users that never logged in.
+func (r *UserRepository) ListInactive(olderThan time.Duration, limit, offset int) ([]*User, error) {
+	rows, err := r.db.Query(
+		`SELECT id, email, password, role, api_key, is_active, last_login_at, created_at, updated_at
+		FROM users
+		WHERE is_active = ? AND (last_login_at IS NULL OR last_login_at < ?)
+		ORDER BY id ASC
+		LIMIT ? OFFSET ?`,
+		true,
+		time.Now().UTC().Add(-olderThan),
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanUsers(rows)
+}
+
+// Count returns the total number of users.
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// CountByStatus returns the number of active and inactive users.
+func (r *UserRepository) CountByStatus() (int64, int64, error) {
+	var active, inactive int64
+	err := r.db.QueryRow(
+		`SELECT
+			COALESCE(SUM(CASE WHEN is_active THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN is_active THEN 0 ELSE 1 END), 0)
+		FROM users`,
+	).Scan(&active, &inactive)
+	return active, inactive, err
+}
+
+// scanUsers scans user rows into a slice.
+func (r *UserRepository) scanUsers(rows *sql.Rows) ([]*User, error) {
 	var users []*User
 	for rows.Next() {
 		user := &User{}
@@ -225,13 +281,6 @@ func (r *UserRepository) List(limit, offset int) ([]*User, error) {
 	return users, rows.Err()
 }
 
-// Count returns the total number of users.
-func (r *UserRepository) Count() (int64, error) {
-	var count int64
-	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
-	return count, err
-}
-
 // UserMeta represents metadata associated with a user.
 type UserMeta struct {
 	ID        int64
@@ -244,12 +293,12 @@ type UserMeta struct {
 
 // UserMetaRepository handles database operations for user metadata.
 type UserMetaRepository struct {
-	db *sql.DB
+	db Querier
 }
 
 // NewUserMetaRepository creates a new user meta repository.
-func NewUserMetaRepository(db *sql.DB) *UserMetaRepository {
-	return &UserMetaRepository{db: db}
+func NewUserMetaRepository(db Querier) *UserMetaRepository {
+	return &UserMetaRepository{db: withBusyRetry(db)}
 }
 
 // Create inserts new metadata for a user.
@@ -291,6 +340,60 @@ func (r *UserMetaRepository) Get(userID int64, key string) (*UserMeta, error) {
 	return meta, nil
 }
 
+// GetByKeyValue retrieves the metadata entry holding a specific key and value.
+func (r *UserMetaRepository) GetByKeyValue(key, value string) (*UserMeta, error) {
+	meta := &UserMeta{}
+	err := r.db.QueryRow(
+		`SELECT id, key, value, user_id, created_at, updated_at
+		FROM users_meta
+		WHERE key = ? AND value = ?`,
+		key,
+		value,
+	).Scan(
+		&meta.ID,
+		&meta.Key,
+		&meta.Value,
+		&meta.UserID,
+		&meta.CreatedAt,
+		&meta.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// ListUserIDsByKeyValue retrieves the IDs of users holding a specific metadata key and value.
+func (r *UserMetaRepository) ListUserIDsByKeyValue(key, value string) ([]int64, error) {
+	rows, err := r.db.Query(
+		`SELECT user_id FROM users_meta
+		WHERE key = ? AND value = ?
+		ORDER BY user_id`,
+		key,
+		value,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
 // Update updates metadata for a user.
 func (r *UserMetaRepository) Update(userID int64, key, value string) error {
 	_, err := r.db.Exec(
@@ -305,6 +408,36 @@ func (r *UserMetaRepository) Update(userID int64, key, value string) error {
 	return err
 }
 
+// CreateIfMissing inserts metadata for a user unless the key already exists.
+func (r *UserMetaRepository) CreateIfMissing(userID int64, key, value string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO users_meta (user_id, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, key) DO NOTHING`,
+		userID,
+		key,
+		value,
+	)
+	return err
+}
+
+// Increment adds delta to an integer metadata entry in a single statement,
+// creating the entry when it is missing, and returns the new value.
+func (r *UserMetaRepository) Increment(userID int64, key string, delta int) (int, error) {
+	var value int
+	err := r.db.QueryRow(
+		`INSERT INTO users_meta (user_id, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			value = CAST(CAST(users_meta.value AS INTEGER) + ? AS TEXT), updated_at = ?
+		RETURNING CAST(value AS INTEGER)`,
+		userID,
+		key,
+		strconv.Itoa(delta),
+		delta,
+		time.Now().UTC(),
+	).Scan(&value)
+	return value, err
+}
+
 // Delete removes metadata for a user.
 func (r *UserMetaRepository) Delete(userID int64, key string) error {
 	_, err := r.db.Exec(
@@ -315,6 +448,35 @@ func (r *UserMetaRepository) Delete(userID int64, key string) error {
 	return err
 }
 
+// DeleteIfValue removes metadata for a user only while it still holds value.
+// It reports whether the entry was removed.
+func (r *UserMetaRepository) DeleteIfValue(userID int64, key, value string) (bool, error) {
+	result, err := r.db.Exec(
+		"DELETE FROM users_meta WHERE user_id = ? AND key = ? AND value = ?",
+		userID,
+		key,
+		value,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DeleteByUserID removes all metadata of a user.
+func (r *UserMetaRepository) DeleteByUserID(userID int64) error {
+	_, err := r.db.Exec("DELETE FROM users_meta WHERE user_id = ?", userID)
+	return err
+}
+
+// DeleteAll removes the metadata of all users.
+func (r *UserMetaRepository) DeleteAll() error {
+	_, err := r.db.Exec("DELETE FROM users_meta")
+	return err
+}
+
 // ListByUser retrieves all metadata for a user.
 func (r *UserMetaRepository) ListByUser(userID int64) ([]*UserMeta, error) {
 	rows, err := r.db.Query(
@@ -348,6 +510,91 @@ func (r *UserMetaRepository) ListByUser(userID int64) ([]*UserMeta, error) {
 	return metadata, rows.Err()
 }
 
+// GetAll retrieves all metadata of a user as a key value map.
+func (r *UserMetaRepository) GetAll(userID int64) (map[string]string, error) {
+	metadata, err := r.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]string, len(metadata))
+	for _, meta := range metadata {
+		entries[meta.Key] = meta.Value
+	}
+
+	return entries, nil
+}
+
+// GetKeysForUsers retrieves the given metadata keys of several users with a
+// single query, as a map of user ID to key value map. Users holding none of
+// the keys are left out.
+func (r *UserMetaRepository) GetKeysForUsers(userIDs []int64, keys []string) (map[int64]map[string]string, error) {
+	entries := make(map[int64]map[string]string)
+	if len(userIDs) == 0 || len(keys) == 0 {
+		return entries, nil
+	}
+
+	args := make([]interface{}, 0, len(userIDs)+len(keys))
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	rows, err := r.db.Query(
+		fmt.Sprintf(
+			`SELECT user_id, key, value FROM users_meta
+			WHERE user_id IN (%s) AND key IN (%s)`,
+			placeholders(len(userIDs)),
+			placeholders(len(keys)),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var key, value string
+		if err := rows.Scan(&userID, &key, &value); err != nil {
+			return nil, err
+		}
+		if entries[userID] == nil {
+			entries[userID] = make(map[string]string)
+		}
+		entries[userID][key] = value
+	}
+
+	return entries, rows.Err()
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// SetMultiple inserts or updates several metadata entries of a user. Run it
+// inside a transaction so a failure leaves none of the entries changed.
+func (r *UserMetaRepository) SetMultiple(userID int64, entries map[string]string) error {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	// A stable order keeps concurrent writers from locking rows in different orders
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := r.Upsert(userID, key, entries[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Upsert inserts or updates metadata for a user.
 func (r *UserMetaRepository) Upsert(userID int64, key, value string) error {
 	existing, err := r.Get(userID, key)
@@ -584,6 +584,41 @@ func TestUnitUserRepository_List(t *testing.T) {
 	})
 }
 
+func TestUnitUserRepository_ListInactive(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(conn.DB)
+
+	users := []*User{
+		{Email: "recent@example.com", LastLoginAt: time.Now().UTC().Add(-time.Hour), IsActive: true},
+		{Email: "dormant@example.com", LastLoginAt: time.Now().UTC().Add(-100 * 24 * time.Hour), IsActive: true},
+		{Email: "never@example.com", LastLoginAt: time.Time{}, IsActive: true},
+		{Email: "disabled@example.com", LastLoginAt: time.Now().UTC().Add(-100 * 24 * time.Hour), IsActive: false},
+	}
+	for i, user := range users {
+		user.Password = "password"
+		user.Role = "user"
+		user.APIKey = "key-" + string(rune('0'+i))
+		require.NoError(t, repo.Create(user))
+	}
+
+	t.Run("List dormant and never logged in users", func(t *testing.T) {
+		inactive, err := repo.ListInactive(90*24*time.Hour, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, inactive, 2)
+		assert.Equal(t, "dormant@example.com", inactive[0].Email)
+		assert.Equal(t, "never@example.com", inactive[1].Email)
+	})
+
+	t.Run("List with pagination", func(t *testing.T) {
+		inactive, err := repo.ListInactive(90*24*time.Hour, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, inactive, 1)
+		assert.Equal(t, "never@example.com", inactive[0].Email)
+	})
+}
+
 func TestUnitUserRepository_Count(t *testing.T) {
 	conn, cleanup := setupUserTestDB(t)
 	defer cleanup()
@@ -639,6 +674,37 @@ func TestUnitUserRepository_Count(t *testing.T) {
 	})
 }
 
+func TestUnitUserRepository_CountByStatus(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(conn.DB)
+
+	t.Run("Count empty table", func(t *testing.T) {
+		active, inactive, err := repo.CountByStatus()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), active)
+		assert.Equal(t, int64(0), inactive)
+	})
+
+	t.Run("Count active and inactive users", func(t *testing.T) {
+		for i := 1; i <= 5; i++ {
+			user := &User{
+				Email:    "status" + string(rune('0'+i)) + "@example.com",
+				Password: "password",
+				Role:     "user",
+				IsActive: i%2 == 1,
+			}
+			require.NoError(t, repo.Create(user))
+		}
+
+		active, inactive, err := repo.CountByStatus()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), active)
+		assert.Equal(t, int64(2), inactive)
+	})
+}
+
 func TestUnitUserMetaRepository_Create(t *testing.T) {
 	conn, cleanup := setupUserTestDB(t)
 	defer cleanup()
@@ -767,6 +833,39 @@ func TestUnitUserMetaRepository_Get(t *testing.T) {
 	})
 }
 
+func TestUnitUserMetaRepository_GetByKeyValue(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	userRepo := NewUserRepository(conn.DB)
+	metaRepo := NewUserMetaRepository(conn.DB)
+
+	t.Run("Get metadata by key and value", func(t *testing.T) {
+		user := &User{
+			Email:    "keyvalue@example.com",
+			Password: "password",
+			Role:     "user",
+			IsActive: true,
+		}
+		err := userRepo.Create(user)
+		require.NoError(t, err)
+
+		err = metaRepo.Create(user.ID, "oidc_subject", "https://idp.example.com|1234")
+		require.NoError(t, err)
+
+		meta, err := metaRepo.GetByKeyValue("oidc_subject", "https://idp.example.com|1234")
+		assert.NoError(t, err)
+		assert.NotNil(t, meta)
+		assert.Equal(t, user.ID, meta.UserID)
+	})
+
+	t.Run("Get metadata by unknown value", func(t *testing.T) {
+		meta, err := metaRepo.GetByKeyValue("oidc_subject", "unknown")
+		assert.NoError(t, err)
+		assert.Nil(t, meta)
+	})
+}
+
 func TestUnitUserMetaRepository_Update(t *testing.T) {
 	conn, cleanup := setupUserTestDB(t)
 	defer cleanup()
@@ -841,6 +940,94 @@ func TestUnitUserMetaRepository_Update(t *testing.T) {
 	})
 }
 
+func TestUnitUserMetaRepository_SetMultiple(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	userRepo := NewUserRepository(conn.DB)
+	metaRepo := NewUserMetaRepository(conn.DB)
+
+	user := &User{
+		Email:    "setmultiple@example.com",
+		Password: "password",
+		Role:     "user",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+	require.NoError(t, metaRepo.Create(user.ID, "theme", "dark"))
+
+	err := metaRepo.SetMultiple(user.ID, map[string]string{
+		"theme":    "light",
+		"language": "en",
+	})
+	require.NoError(t, err)
+
+	entries, err := metaRepo.GetAll(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"theme": "light", "language": "en"}, entries)
+
+	entries, err = metaRepo.GetAll(user.ID + 1)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestUnitUserMetaRepository_Counters(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	userRepo := NewUserRepository(conn.DB)
+	metaRepo := NewUserMetaRepository(conn.DB)
+
+	user := &User{
+		Email:    "counters@example.com",
+		Password: "password",
+		Role:     "user",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+
+	t.Run("Increment creates and updates the entry", func(t *testing.T) {
+		value, err := metaRepo.Increment(user.ID, "count", 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, value)
+
+		value, err = metaRepo.Increment(user.ID, "count", 4)
+		require.NoError(t, err)
+		assert.Equal(t, 5, value)
+
+		value, err = metaRepo.Increment(user.ID, "count", -2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, value)
+
+		meta, err := metaRepo.Get(user.ID, "count")
+		require.NoError(t, err)
+		assert.Equal(t, "3", meta.Value)
+	})
+
+	t.Run("CreateIfMissing keeps an existing value", func(t *testing.T) {
+		require.NoError(t, metaRepo.CreateIfMissing(user.ID, "since", "first"))
+		require.NoError(t, metaRepo.CreateIfMissing(user.ID, "since", "second"))
+
+		meta, err := metaRepo.Get(user.ID, "since")
+		require.NoError(t, err)
+		assert.Equal(t, "first", meta.Value)
+	})
+
+	t.Run("DeleteIfValue only removes a matching value", func(t *testing.T) {
+		deleted, err := metaRepo.DeleteIfValue(user.ID, "since", "second")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = metaRepo.DeleteIfValue(user.ID, "since", "first")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		meta, err := metaRepo.Get(user.ID, "since")
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+	})
+}
+
 func TestUnitUserMetaRepository_Delete(t *testing.T) {
 	conn, cleanup := setupUserTestDB(t)
 	defer cleanup()
@@ -1061,3 +1248,73 @@ func TestUnitUserMetaRepository_Upsert(t *testing.T) {
 		assert.Equal(t, 1, countEntries, "Should only have one counter entry")
 	})
 }
+
+func TestUnitUserMetaRepository_ListUserIDsByKeyValue(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	userRepo := NewUserRepository(conn.DB)
+	metaRepo := NewUserMetaRepository(conn.DB)
+
+	var userIDs []int64
+	for i, email := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		user := &User{
+			Email:    email,
+			Password: "password",
+			Role:     "user",
+			IsActive: true,
+		}
+		require.NoError(t, userRepo.Create(user))
+		userIDs = append(userIDs, user.ID)
+
+		value := "1"
+		if i == 1 {
+			value = "0"
+		}
+		require.NoError(t, metaRepo.Create(user.ID, "seeded", value))
+	}
+
+	ids, err := metaRepo.ListUserIDsByKeyValue("seeded", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{userIDs[0], userIDs[2]}, ids)
+
+	ids, err = metaRepo.ListUserIDsByKeyValue("missing", "1")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestUnitUserMetaRepository_GetKeysForUsers(t *testing.T) {
+	conn, cleanup := setupUserTestDB(t)
+	defer cleanup()
+
+	userRepo := NewUserRepository(conn.DB)
+	metaRepo := NewUserMetaRepository(conn.DB)
+
+	var userIDs []int64
+	for _, email := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		user := &User{
+			Email:    email,
+			Password: "password",
+			Role:     "user",
+			IsActive: true,
+		}
+		require.NoError(t, userRepo.Create(user))
+		userIDs = append(userIDs, user.ID)
+	}
+
+	require.NoError(t, metaRepo.Create(userIDs[0], "auth_provider", "oidc"))
+	require.NoError(t, metaRepo.Create(userIDs[0], "theme", "dark"))
+	require.NoError(t, metaRepo.Create(userIDs[1], "email_verification_token", "hash"))
+	require.NoError(t, metaRepo.Create(userIDs[2], "auth_provider", "oidc"))
+
+	entries, err := metaRepo.GetKeysForUsers(userIDs[:2], []string{"auth_provider", "email_verification_token"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]map[string]string{
+		userIDs[0]: {"auth_provider": "oidc"},
+		userIDs[1]: {"email_verification_token": "hash"},
+	}, entries)
+
+	entries, err = metaRepo.GetKeysForUsers(nil, []string{"auth_provider"})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
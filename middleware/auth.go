@@ -39,11 +39,9 @@ func SessionAuth() func(http.Handler) http.Handler {
 			apiKey := r.Header.Get("X-API-Key")
 			if apiKey != "" {
 				user, err := db.NewUserRepository(db.GetDB()).GetByAPIKey(apiKey)
-				if err != nil {
+				if err != nil || user == nil || !user.IsActive {
 					log.Info().Err(err).Str("path", r.URL.Path).Msg("API key validation failed")
-					service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-						"errorMessage": "Invalid API key",
-					})
+					service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Invalid API key")
 					return
 				}
 				log.Info().Str("path", r.URL.Path).Msg("API key validation successful")
@@ -57,9 +55,7 @@ func SessionAuth() func(http.Handler) http.Handler {
 			sessionToken := service.GetCookie(r, "_tut_session")
 			if sessionToken == "" {
 				log.Info().Str("path", r.URL.Path).Msg("No session cookie found")
-				service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-					"errorMessage": "Not authenticated",
-				})
+				service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
 				return
 			}
 
@@ -72,11 +68,8 @@ func SessionAuth() func(http.Handler) http.Handler {
 			user, _, err := sessionManager.ValidateSession(sessionToken)
 			if err != nil {
 				service.DeleteCookie(w, "_tut_session")
-				sessionManager.RevokeUserSessions(user.ID)
 				log.Info().Err(err).Str("path", r.URL.Path).Msg("Session validation failed")
-				service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-					"errorMessage": "Invalid or expired session",
-				})
+				service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Invalid or expired session")
 				return
 			}
 
@@ -96,6 +89,11 @@ func shouldSkipAuth(path string) bool {
 		return true
 	}
 
+	// Skip auth for the OpenAPI document
+	if path == "/api/v1/openapi.json" {
+		return true
+	}
+
 	return false
 }
 
@@ -0,0 +1,110 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationSessionAuth tests deactivated users lose both API key and session access
+func TestIntegrationSessionAuth(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_session_auth.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	for _, query := range []string{
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL DEFAULT 'user',
+			api_key VARCHAR(255) UNIQUE,
+			is_active BOOLEAN DEFAULT 1,
+			last_login_at DATETIME NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			token VARCHAR(255) NOT NULL UNIQUE,
+			user_id INTEGER NOT NULL,
+			ip_address VARCHAR(45),
+			user_agent VARCHAR(500),
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+	} {
+		_, err := db.GetDB().Exec(query)
+		require.NoError(t, err)
+	}
+
+	userRepo := db.NewUserRepository(db.GetDB())
+	user := &db.User{
+		Email:    "user@example.com",
+		Password: "hashed",
+		Role:     db.UserRoleUser,
+		APIKey:   "api-key",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+
+	sessionManager := module.NewSessionManager(db.NewSessionRepository(db.GetDB()), userRepo)
+	session, err := sessionManager.CreateSession(user.ID, time.Hour, "127.0.0.1", "test")
+	require.NoError(t, err)
+
+	handler := SessionAuth()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(apiKey, sessionToken string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if sessionToken != "" {
+			req.AddCookie(&http.Cookie{Name: "_tut_session", Value: sessionToken})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Active user", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request("api-key", ""))
+		assert.Equal(t, http.StatusOK, request("", session.Token))
+	})
+
+	t.Run("Unknown API key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request("unknown", ""))
+	})
+
+	t.Run("Deactivated user", func(t *testing.T) {
+		user.IsActive = false
+		require.NoError(t, userRepo.Update(user))
+
+		assert.Equal(t, http.StatusUnauthorized, request("api-key", ""))
+		assert.Equal(t, http.StatusUnauthorized, request("", session.Token))
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		require.NoError(t, sessionManager.RevokeUserSessions(user.ID))
+
+		assert.Equal(t, http.StatusUnauthorized, request("", session.Token))
+	})
+}
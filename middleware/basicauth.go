@@ -7,6 +7,8 @@ package middleware
 import (
 	"crypto/subtle"
 	"net/http"
+
+	"github.com/clivern/tut/service"
 )
 
 // BasicAuth creates a basic authentication middleware
@@ -22,7 +24,7 @@ func BasicAuth(username, password string) func(http.Handler) http.Handler {
 
 			if !ok || !userMatch || !passMatch {
 				w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Invalid credentials")
 				return
 			}
 
@@ -0,0 +1,47 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnitBasicAuth tests the basic authentication middleware
+func TestUnitBasicAuth(t *testing.T) {
+	handler := BasicAuth("admin", "secret")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("Valid credentials", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/_metrics", nil)
+		r.SetBasicAuth("admin", "secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid credentials", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/_metrics", nil)
+		r.SetBasicAuth("admin", "wrong")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="Restricted"`, w.Header().Get("WWW-Authenticate"))
+
+		var apiErr service.APIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, service.ErrorCodeUnauthorized, apiErr.Code)
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CORS creates a middleware that applies the allowed origins from the settings
+// to the JSON API. Requests from other origins get no CORS headers so the
+// browser blocks them.
+func CORS() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !strings.HasPrefix(r.URL.Path, "/api/v1/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			settings, err := module.NewSettings(db.NewOptionRepository(db.GetDB())).GetCORSSettings()
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to get CORS settings")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, wildcard := matchOrigin(settings.AllowedOrigins, origin)
+			if allowed {
+				if wildcard {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			// Answer preflight requests before they reach the authentication middleware
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
+					w.Header().Set("Access-Control-Max-Age", "600")
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchOrigin checks the origin against the allowed origins, a "*" entry allows
+// any origin without credentials
+func matchOrigin(allowedOrigins []string, origin string) (bool, bool) {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" {
+			return true, true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return true, false
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// IPWhitelist creates a middleware that only lets requests from the given CIDR
+// ranges through. A plain IP is treated as a single address range. An empty
+// list leaves the routes unrestricted, invalid entries are logged and skipped
+// so a typo never opens the routes to everyone.
+func IPWhitelist(cidrs []string) func(http.Handler) http.Handler {
+	networks := parseNetworks(cidrs)
+	restricted := len(cidrs) > 0
+
+	return func(next http.Handler) http.Handler {
+		if !restricted {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP := service.ClientIP(r)
+
+			if !containsIP(networks, net.ParseIP(clientIP)) {
+				log.Info().
+					Str("path", r.URL.Path).
+					Str("clientIP", clientIP).
+					Msg("Request from an IP outside the whitelist")
+				service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "Access denied from this IP address")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// parseNetworks parses the CIDR ranges and plain IPs into networks
+func parseNetworks(cidrs []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		network, err := service.ParseNetwork(cidr)
+		if err != nil {
+			log.Error().Err(err).Str("cidr", cidr).Msg("Invalid IP whitelist entry")
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// containsIP checks if the IP is in any of the networks
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitIPWhitelist(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		cidrs      []string
+		remoteAddr string
+		expected   int
+	}{
+		{"Empty list is unrestricted", nil, "203.0.113.10:1234", http.StatusOK},
+		{"IPv4 inside range", []string{"10.0.0.0/8"}, "10.1.2.3:1234", http.StatusOK},
+		{"IPv4 outside range", []string{"10.0.0.0/8"}, "11.1.2.3:1234", http.StatusForbidden},
+		{"IPv4 inside second range", []string{"10.0.0.0/8", "192.168.1.0/24"}, "192.168.1.20:1234", http.StatusOK},
+		{"IPv4 outside both ranges", []string{"10.0.0.0/8", "192.168.1.0/24"}, "192.168.2.20:1234", http.StatusForbidden},
+		{"IPv6 inside range", []string{"2001:db8::/32"}, "[2001:db8::1]:1234", http.StatusOK},
+		{"IPv6 outside range", []string{"2001:db8::/32"}, "[2001:db9::1]:1234", http.StatusForbidden},
+		{"Plain IP matches itself", []string{"127.0.0.1"}, "127.0.0.1:1234", http.StatusOK},
+		{"Plain IP rejects others", []string{"127.0.0.1"}, "127.0.0.2:1234", http.StatusForbidden},
+		{"Invalid entries deny everything", []string{"not-a-cidr"}, "10.1.2.3:1234", http.StatusForbidden},
+		{"Invalid entries are skipped", []string{"not-a-cidr", "10.0.0.0/8"}, "10.1.2.3:1234", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+
+			IPWhitelist(tt.cidrs)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
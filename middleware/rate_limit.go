@@ -0,0 +1,102 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// rateWindow tracks the requests of a single client in the current window
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// RateLimiter is a fixed window rate limiter keyed by client IP
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateWindow
+	nextSweep time.Time
+}
+
+// allow records a request and reports whether it is within the limit
+func (l *RateLimiter) allow(ip string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows from time to time to keep memory bounded
+	if now.After(l.nextSweep) {
+		for key, client := range l.clients {
+			if now.After(client.resetAt) {
+				delete(l.clients, key)
+			}
+		}
+		l.nextSweep = now.Add(l.window)
+	}
+
+	client, ok := l.clients[ip]
+	if !ok || now.After(client.resetAt) {
+		client = &rateWindow{resetAt: now.Add(l.window)}
+		l.clients[ip] = client
+	}
+
+	client.count++
+
+	return client.count <= l.limit, client.resetAt.Sub(now)
+}
+
+// NewRateLimiter creates a rate limiter that allows at most limit requests
+// per client IP within the given window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// SetLimit changes the allowed requests per window, it applies to the
+// current windows too
+func (l *RateLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = limit
+}
+
+// Handler is the rate limiting middleware
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := service.ClientIP(r)
+
+		allowed, retryAfter := l.allow(ip, time.Now().UTC())
+		if !allowed {
+			log.Info().Str("path", r.URL.Path).Str("ip", ip).Msg("Rate limit exceeded")
+			seconds := int(retryAfter.Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			service.WriteErrorWithDetails(w, http.StatusTooManyRequests, service.ErrorCodeTooManyRequests, "Too many requests, please try again later", map[string]interface{}{
+				"retryAfter": seconds,
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimit creates a middleware that allows at most limit requests
+// per client IP within the given window
+func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	return NewRateLimiter(limit, window).Handler
+}
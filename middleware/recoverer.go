@@ -0,0 +1,82 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// headerTracker records whether the response headers were already sent
+type headerTracker struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (ht *headerTracker) WriteHeader(code int) {
+	ht.wroteHeader = true
+	ht.ResponseWriter.WriteHeader(code)
+}
+
+func (ht *headerTracker) Write(b []byte) (int, error) {
+	ht.wroteHeader = true
+	return ht.ResponseWriter.Write(b)
+}
+
+func (ht *headerTracker) Flush() {
+	if flusher, ok := ht.ResponseWriter.(http.Flusher); ok {
+		ht.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer so http.ResponseController
+// reaches the connection through the tracker
+func (ht *headerTracker) Unwrap() http.ResponseWriter {
+	return ht.ResponseWriter
+}
+
+// Recoverer creates a middleware that recovers from panics, logs the stack
+// and returns a JSON error body
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker := &headerTracker{ResponseWriter: w}
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			// Let net/http abort the connection as intended
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			log.Error().
+				Interface("panic", rec).
+				Str("requestId", GetRequestID(r.Context())).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Bytes("stack", debug.Stack()).
+				Msg("Recovered from panic")
+
+			// The response is already on its way, abort the connection so the
+			// client sees a failure instead of a complete looking response
+			if tracker.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+
+			service.WriteInternalError(w, "Internal server error")
+		}()
+
+		next.ServeHTTP(tracker, r)
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitRecoverer(t *testing.T) {
+	t.Run("Panic before the response gets a 500 JSON error", func(t *testing.T) {
+		handler := Recoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/action/profile", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "internal_error", body["errorCode"])
+	})
+
+	t.Run("Panic after the headers aborts the connection", func(t *testing.T) {
+		handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"items":[`))
+			panic("boom")
+		}))
+
+		w := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/action/activities/export", nil))
+		})
+	})
+}
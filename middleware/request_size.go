@@ -7,13 +7,37 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"sync/atomic"
 
 	"github.com/rs/zerolog/log"
 )
 
+// DefaultMaxJSONBodyBytes is the request body limit used when none is configured
+const DefaultMaxJSONBodyBytes = 1024 * 1024
+
+// maxJSONBodyBytes holds the request body limit, it can change while serving
+var maxJSONBodyBytes atomic.Int64
+
+// SetMaxJSONBodyBytes sets the request body limit of RequestSizeLimit,
+// zero or less uses DefaultMaxJSONBodyBytes
+func SetMaxJSONBodyBytes(maxBytes int64) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxJSONBodyBytes
+	}
+	maxJSONBodyBytes.Store(maxBytes)
+}
+
+// GetMaxJSONBodyBytes returns the current request body limit
+func GetMaxJSONBodyBytes() int64 {
+	if maxBytes := maxJSONBodyBytes.Load(); maxBytes > 0 {
+		return maxBytes
+	}
+	return DefaultMaxJSONBodyBytes
+}
+
 // RequestSizeLimit creates a middleware that limits the size of request bodies
-// maxBytes specifies the maximum allowed size in bytes
-func RequestSizeLimit(maxBytes int64) func(http.Handler) http.Handler {
+// to the limit set with SetMaxJSONBodyBytes
+func RequestSizeLimit() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if !strings.HasPrefix(r.URL.Path, "/api/v1/") {
@@ -22,7 +46,7 @@ func RequestSizeLimit(maxBytes int64) func(http.Handler) http.Handler {
 				return
 			}
 
-			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			r.Body = http.MaxBytesReader(w, r.Body, GetMaxJSONBodyBytes())
 
 			next.ServeHTTP(w, r)
 		})
@@ -21,9 +21,7 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 			user, ok := GetUserFromContext(r.Context())
 			if !ok || user == nil {
 				log.Info().Str("path", r.URL.Path).Msg("User not found in context for role check")
-				service.WriteJSON(w, http.StatusUnauthorized, map[string]interface{}{
-					"errorMessage": "Not authenticated",
-				})
+				service.WriteError(w, http.StatusUnauthorized, service.ErrorCodeUnauthorized, "Not authenticated")
 				return
 			}
 
@@ -33,9 +31,7 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 					Str("path", r.URL.Path).
 					Int64("userID", user.ID).
 					Msg("Inactive user attempted to access protected route")
-				service.WriteJSON(w, http.StatusForbidden, map[string]interface{}{
-					"errorMessage": "Account is inactive",
-				})
+				service.WriteError(w, http.StatusForbidden, service.ErrorCodeAccountInactive, "Account is inactive")
 				return
 			}
 
@@ -55,9 +51,7 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 					Str("userRole", user.Role).
 					Strs("allowedRoles", allowedRoles).
 					Msg("User does not have required role")
-				service.WriteJSON(w, http.StatusForbidden, map[string]interface{}{
-					"errorMessage": "Insufficient permissions",
-				})
+				service.WriteError(w, http.StatusForbidden, service.ErrorCodeForbidden, "Insufficient permissions")
 				return
 			}
 
@@ -0,0 +1,52 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SecurityHeadersConfig holds the security header values
+type SecurityHeadersConfig struct {
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	PermissionsPolicy     string
+	XSSProtection         string
+	// StrictTransportSecurity is only set when the server terminates TLS
+	StrictTransportSecurity string
+}
+
+// SecurityHeaders creates a middleware that sets security headers on every response
+// The content security policy is only sent for non API routes which serve HTML
+func SecurityHeaders(config SecurityHeadersConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+
+			if config.FrameOptions != "" {
+				w.Header().Set("X-Frame-Options", config.FrameOptions)
+			}
+			if config.ReferrerPolicy != "" {
+				w.Header().Set("Referrer-Policy", config.ReferrerPolicy)
+			}
+			if config.PermissionsPolicy != "" {
+				w.Header().Set("Permissions-Policy", config.PermissionsPolicy)
+			}
+			if config.XSSProtection != "" {
+				w.Header().Set("X-XSS-Protection", config.XSSProtection)
+			}
+			if config.StrictTransportSecurity != "" {
+				w.Header().Set("Strict-Transport-Security", config.StrictTransportSecurity)
+			}
+			if config.ContentSecurityPolicy != "" && !strings.HasPrefix(r.URL.Path, "/api/") {
+				w.Header().Set("Content-Security-Policy", config.ContentSecurityPolicy)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
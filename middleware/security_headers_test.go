@@ -0,0 +1,61 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnitSecurityHeaders tests the headers set by the security headers middleware
+func TestUnitSecurityHeaders(t *testing.T) {
+	config := SecurityHeadersConfig{
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: "default-src 'self'",
+		PermissionsPolicy:     "camera=(), microphone=(), geolocation=()",
+		XSSProtection:         "0",
+	}
+
+	serve := func(config SecurityHeadersConfig, path string) http.Header {
+		handler := SecurityHeaders(config)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Header()
+	}
+
+	t.Run("Headers on API responses", func(t *testing.T) {
+		headers := serve(config, "/api/v1/public/_health")
+
+		assert.Equal(t, "nosniff", headers.Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", headers.Get("X-Frame-Options"))
+		assert.Equal(t, "strict-origin-when-cross-origin", headers.Get("Referrer-Policy"))
+		assert.Equal(t, "camera=(), microphone=(), geolocation=()", headers.Get("Permissions-Policy"))
+		assert.Equal(t, "0", headers.Get("X-XSS-Protection"))
+		assert.Empty(t, headers.Get("Content-Security-Policy"))
+		assert.Empty(t, headers.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("Content security policy on pages", func(t *testing.T) {
+		headers := serve(config, "/login")
+
+		assert.Equal(t, "default-src 'self'", headers.Get("Content-Security-Policy"))
+	})
+
+	t.Run("Strict transport security with TLS", func(t *testing.T) {
+		tlsConfig := config
+		tlsConfig.StrictTransportSecurity = "max-age=31536000; includeSubDomains"
+
+		headers := serve(tlsConfig, "/api/v1/public/_health")
+
+		assert.Equal(t, "max-age=31536000; includeSubDomains", headers.Get("Strict-Transport-Security"))
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/module"
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// setupExemptPaths are the API routes that work before the application is installed
+var setupExemptPaths = map[string]bool{
+	"/api/v1/public/_health":             true,
+	"/api/v1/public/_ready":              true,
+	"/api/v1/public/_metrics":            true,
+	"/api/v1/public/action/setup":        true,
+	"/api/v1/public/action/setup/status": true,
+	"/api/v1/openapi.json":               true,
+}
+
+// installed caches the installed state once it is true so installed
+// applications skip the lookup. Only a reset clears it.
+var installed atomic.Bool
+
+// ForgetInstalled clears the cached installed state after the application
+// data is reset
+func ForgetInstalled() {
+	installed.Store(false)
+}
+
+// RequireSetup creates a middleware that rejects API requests with a 503
+// until the application is installed. Pages and assets are still served so
+// the setup page can load.
+func RequireSetup() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if installed.Load() || !strings.HasPrefix(r.URL.Path, "/api/v1/") || setupExemptPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			setupModule := module.NewSetup(
+				db.NewOptionRepository(db.GetDB()),
+				db.NewUserRepository(db.GetDB()),
+			)
+
+			isInstalled, err := setupModule.IsInstalled()
+			if err != nil {
+				log.Error().Err(err).Str("path", r.URL.Path).Msg("Failed to check the installation state")
+				service.WriteInternalError(w, "Failed to check the installation state")
+				return
+			}
+
+			if !isInstalled {
+				log.Info().Str("path", r.URL.Path).Msg("Request rejected, application is not installed")
+				service.WriteError(w, http.StatusServiceUnavailable, service.ErrorCodeSetupRequired, "Application is not installed, please complete the setup first")
+				return
+			}
+
+			installed.Store(true)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
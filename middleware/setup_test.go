@@ -0,0 +1,86 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationRequireSetup tests rejecting API requests until the application is installed
+func TestIntegrationRequireSetup(t *testing.T) {
+	db.CloseDB()
+
+	tmpFile := "/tmp/test_require_setup.db"
+	defer os.Remove(tmpFile)
+
+	require.NoError(t, db.InitDB(db.Config{Driver: "sqlite", DataSource: tmpFile}))
+	defer db.CloseDB()
+
+	ForgetInstalled()
+	defer ForgetInstalled()
+
+	_, err := db.GetDB().Exec(`
+		CREATE TABLE options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL UNIQUE,
+			value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	handler := RequireSetup()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	t.Run("Not installed", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, serve("/api/v1/action/profile"))
+		assert.Equal(t, http.StatusServiceUnavailable, serve("/api/v1/public/action/login/options"))
+		assert.Equal(t, http.StatusOK, serve("/api/v1/public/action/setup/status"))
+		assert.Equal(t, http.StatusOK, serve("/api/v1/public/_health"))
+		assert.Equal(t, http.StatusOK, serve("/setup"))
+	})
+
+	t.Run("Database errors are internal errors", func(t *testing.T) {
+		_, err := db.GetDB().Exec("ALTER TABLE options RENAME TO options_backup")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusInternalServerError, serve("/api/v1/action/profile"))
+
+		_, err = db.GetDB().Exec("ALTER TABLE options_backup RENAME TO options")
+		require.NoError(t, err)
+	})
+
+	t.Run("Installed", func(t *testing.T) {
+		require.NoError(t, db.NewOptionRepository(db.GetDB()).Create("is_installed", "1"))
+
+		assert.Equal(t, http.StatusOK, serve("/api/v1/action/profile"))
+		assert.Equal(t, http.StatusOK, serve("/api/v1/public/action/login/options"))
+	})
+
+	t.Run("Installed state is cached", func(t *testing.T) {
+		_, err := db.GetDB().Exec("DELETE FROM options")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, serve("/api/v1/action/profile"))
+
+		ForgetInstalled()
+		assert.Equal(t, http.StatusServiceUnavailable, serve("/api/v1/action/profile"))
+	})
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/clivern/tut/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Timeout creates a middleware for JSON endpoints that answers with a 408
+// JSON error when the handler does not finish in time. The handler runs with
+// a context deadline and its response is buffered, so a late handler can not
+// write into the timeout response. Do not use it for streaming responses.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{header: make(http.Header), status: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				dst := w.Header()
+				for key, values := range tw.header {
+					dst[key] = values
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					log.Info().Str("path", r.URL.Path).Dur("timeout", timeout).Msg("Request timed out")
+					service.WriteError(w, http.StatusRequestTimeout, service.ErrorCodeRequestTimeout, fmt.Sprintf("Request did not complete within %s", timeout))
+				}
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the handler response until it completes
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+}
+
+// Header returns the buffered response headers
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write buffers the response body
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.written = true
+	return tw.body.Write(p)
+}
+
+// WriteHeader records the response status code
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.written {
+		return
+	}
+	tw.written = true
+	tw.status = status
+}
+
+// TransferIdleTimeout creates a middleware for streaming endpoints. Instead of
+// a total deadline, every write extends the connection write deadline by the
+// idle timeout, so a transfer runs as long as the client keeps reading and is
+// aborted once it stalls. When the writer does not support write deadlines an
+// idle timer cancels the request context and fails later writes instead.
+func TransferIdleTimeout(idle time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if idle <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller := http.NewResponseController(w)
+			if err := controller.SetWriteDeadline(time.Now().Add(idle)); err == nil {
+				next.ServeHTTP(&idleWriter{ResponseWriter: w, controller: controller, idle: idle}, r)
+				return
+			}
+
+			log.Warn().Str("path", r.URL.Path).Msg("Write deadlines are not supported, falling back to an idle timer")
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			timer := time.AfterFunc(idle, cancel)
+			defer timer.Stop()
+
+			next.ServeHTTP(&idleWriter{
+				ResponseWriter: w,
+				controller:     controller,
+				idle:           idle,
+				timer:          timer,
+				ctx:            ctx,
+			}, r.WithContext(ctx))
+		})
+	}
+}
+
+// idleWriter extends the write deadline, or resets the idle timer, on every write
+type idleWriter struct {
+	http.ResponseWriter
+	controller *http.ResponseController
+	idle       time.Duration
+	// timer and ctx are only set when write deadlines are not supported
+	timer *time.Timer
+	ctx   context.Context
+}
+
+// Write extends the write deadline and writes to the response
+func (iw *idleWriter) Write(p []byte) (int, error) {
+	if iw.timer == nil {
+		iw.controller.SetWriteDeadline(time.Now().Add(iw.idle))
+		return iw.ResponseWriter.Write(p)
+	}
+
+	if iw.ctx.Err() != nil {
+		return 0, http.ErrHandlerTimeout
+	}
+	iw.timer.Reset(iw.idle)
+	return iw.ResponseWriter.Write(p)
+}
+
+// Flush flushes the response when the underlying writer supports it
+func (iw *idleWriter) Flush() {
+	iw.controller.Flush()
+}
+
+// Unwrap returns the underlying response writer
+func (iw *idleWriter) Unwrap() http.ResponseWriter {
+	return iw.ResponseWriter
+}
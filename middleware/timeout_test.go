@@ -0,0 +1,104 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitTimeout(t *testing.T) {
+	t.Run("Fast handler response is passed through", func(t *testing.T) {
+		handler := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Test", "1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"ok":true}`))
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/action/profile", nil))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Test"))
+		assert.Equal(t, `{"ok":true}`, w.Body.String())
+	})
+
+	t.Run("Slow handler gets a 408 JSON error", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		handler := Timeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			<-release
+			w.Write([]byte("late"))
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/action/profile", nil))
+
+		assert.Equal(t, http.StatusRequestTimeout, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "request_timeout", response["errorCode"])
+	})
+
+	t.Run("Panics reach the caller", func(t *testing.T) {
+		handler := Timeout(time.Second)(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			panic("boom")
+		}))
+
+		assert.PanicsWithValue(t, "boom", func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
+
+func TestUnitTransferIdleTimeout(t *testing.T) {
+	t.Run("Transfers longer than the idle timeout keep running while writing", func(t *testing.T) {
+		server := httptest.NewServer(TransferIdleTimeout(100 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			for i := 0; i < 4; i++ {
+				w.Write([]byte("chunk\n"))
+				http.NewResponseController(w).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+		})))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("chunk\n", 4), string(body))
+	})
+
+	t.Run("Writers without deadline support fall back to an idle timer", func(t *testing.T) {
+		var lateErr error
+		var ctxErr error
+		handler := TransferIdleTimeout(50 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+			time.Sleep(150 * time.Millisecond)
+			_, lateErr = w.Write([]byte("late"))
+			ctxErr = r.Context().Err()
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, "ok", w.Body.String())
+		assert.ErrorIs(t, lateErr, http.ErrHandlerTimeout)
+		assert.ErrorIs(t, ctxErr, context.Canceled)
+	})
+}
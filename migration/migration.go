@@ -14,12 +14,19 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// Executor runs the statements of a migration. Both *sql.DB and *sql.Tx
+// implement it, the manager passes the transaction that records the migration.
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // Migration represents a database migration
 type Migration struct {
 	Version     string
 	Description string
-	Up          func(*sql.DB) error
-	Down        func(*sql.DB) error
+	Up          func(Executor) error
+	Down        func(Executor) error
 }
 
 // Manager handles database migrations
@@ -88,8 +95,8 @@ func (m *Manager) isApplied(version string) (bool, error) {
 }
 
 // recordMigration records a migration as applied
-func (m *Manager) recordMigration(version, description string) error {
-	_, err := m.db.Exec(
+func (m *Manager) recordMigration(tx Executor, version, description string) error {
+	_, err := tx.Exec(
 		"INSERT INTO migrations (version, description, applied_at) VALUES (?, ?, ?)",
 		version,
 		description,
@@ -102,8 +109,8 @@ func (m *Manager) recordMigration(version, description string) error {
 }
 
 // removeMigration removes a migration record
-func (m *Manager) removeMigration(version string) error {
-	_, err := m.db.Exec("DELETE FROM migrations WHERE version = ?", version)
+func (m *Manager) removeMigration(tx Executor, version string) error {
+	_, err := tx.Exec("DELETE FROM migrations WHERE version = ?", version)
 	if err != nil {
 		return fmt.Errorf("failed to remove migration record: %w", err)
 	}
@@ -145,12 +152,12 @@ func (m *Manager) Up() error {
 			return fmt.Errorf("failed to start transaction: %w", err)
 		}
 
-		if err := migration.Up(m.db); err != nil {
+		if err := migration.Up(tx); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("migration %s failed: %w", migration.Version, err)
 		}
 
-		if err := m.recordMigration(migration.Version, migration.Description); err != nil {
+		if err := m.recordMigration(tx, migration.Version, migration.Description); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -179,44 +186,89 @@ func (m *Manager) Up() error {
 
 // Down rolls back the last migration
 func (m *Manager) Down() error {
+	return m.Rollback(1)
+}
+
+// Rollback rolls back the last steps applied migrations in reverse order
+func (m *Manager) Rollback(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be greater than zero, got %d", steps)
+	}
+
 	if err := m.createMigrationsTable(); err != nil {
 		return err
 	}
 
-	var version, description string
-	err := m.db.QueryRow(`
+	rows, err := m.db.Query(`
 		SELECT version, description
 		FROM migrations
 		ORDER BY applied_at DESC, id DESC
-		LIMIT 1
-	`).Scan(&version, &description)
+		LIMIT ?
+	`, steps)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
 
-	if err == sql.ErrNoRows {
+	var applied []Migration
+	for rows.Next() {
+		var version, description string
+		if err := rows.Scan(&version, &description); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+		applied = append(applied, Migration{Version: version, Description: description})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	if len(applied) == 0 {
 		log.Info().Msg("No migrations to roll back")
 		return nil
 	}
-	if err != nil {
-		return fmt.Errorf("failed to get last migration: %w", err)
-	}
 
-	var migration *Migration
-	for i := range m.migrations {
-		if m.migrations[i].Version == version {
-			migration = &m.migrations[i]
-			break
+	// Resolve every migration before rolling back anything
+	migrations := make([]*Migration, 0, len(applied))
+	for _, record := range applied {
+		migration := m.find(record.Version)
+		if migration == nil {
+			return fmt.Errorf("migration %s not found in registered migrations", record.Version)
 		}
+		if migration.Down == nil {
+			return fmt.Errorf("migration %s has no down function", record.Version)
+		}
+		migrations = append(migrations, migration)
 	}
 
-	if migration == nil {
-		return fmt.Errorf("migration %s not found in registered migrations", version)
+	for _, migration := range migrations {
+		if err := m.rollbackMigration(migration); err != nil {
+			return err
+		}
 	}
-	if migration.Down == nil {
-		return fmt.Errorf("migration %s has no down function", version)
+
+	log.Info().
+		Int("count", len(migrations)).
+		Msg("Migrations rolled back successfully")
+
+	return nil
+}
+
+// find returns the registered migration with the given version
+func (m *Manager) find(version string) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
 	}
+	return nil
+}
 
+// rollbackMigration runs the down function of a migration and removes its record
+func (m *Manager) rollbackMigration(migration *Migration) error {
 	log.Info().
-		Str("version", version).
-		Str("description", description).
+		Str("version", migration.Version).
+		Str("description", migration.Description).
 		Msg("Rolling back migration")
 
 	tx, err := m.db.Begin()
@@ -224,12 +276,12 @@ func (m *Manager) Down() error {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	if err := migration.Down(m.db); err != nil {
+	if err := migration.Down(tx); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("rollback of migration %s failed: %w", version, err)
+		return fmt.Errorf("rollback of migration %s failed: %w", migration.Version, err)
 	}
 
-	if err := m.removeMigration(version); err != nil {
+	if err := m.removeMigration(tx, migration.Version); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -239,42 +291,63 @@ func (m *Manager) Down() error {
 	}
 
 	log.Info().
-		Str("version", version).
+		Str("version", migration.Version).
 		Msg("Migration rolled back successfully")
 
 	return nil
 }
 
-// Status shows the status of all migrations
-func (m *Manager) Status() error {
+// MigrationStatus represents the status of a migration
+type MigrationStatus struct {
+	Version     string
+	Description string
+	Applied     bool
+	AppliedAt   *time.Time
+}
+
+// Status returns the status of all registered migrations ordered by version
+func (m *Manager) Status() ([]MigrationStatus, error) {
 	if err := m.createMigrationsTable(); err != nil {
-		return err
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT version, applied_at FROM migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[string]*time.Time)
+	for rows.Next() {
+		var version string
+		var at sql.NullTime
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+		appliedAt[version] = nil
+		if at.Valid {
+			value := at.Time.UTC()
+			appliedAt[version] = &value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
 	sort.Slice(m.migrations, func(i, j int) bool {
 		return m.migrations[i].Version < m.migrations[j].Version
 	})
 
-	log.Info().Msg("Migration Status:")
-	log.Info().Msg("==================")
-
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
 	for _, migration := range m.migrations {
-		applied, err := m.isApplied(migration.Version)
-		if err != nil {
-			return err
-		}
-
-		status := "Pending"
-		if applied {
-			status = "Applied"
-		}
-
-		log.Info().
-			Str("version", migration.Version).
-			Str("description", migration.Description).
-			Str("status", status).
-			Msg("")
+		at, applied := appliedAt[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     applied,
+			AppliedAt:   at,
+		})
 	}
 
-	return nil
+	return statuses, nil
 }
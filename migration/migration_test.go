@@ -0,0 +1,182 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package migration
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMigrationTestDB(t *testing.T) *sql.DB {
+	testDB, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tut.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { testDB.Close() })
+
+	return testDB
+}
+
+func tableExists(t *testing.T, testDB *sql.DB, name string) bool {
+	var count int
+	err := testDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestUnitRollback(t *testing.T) {
+	t.Run("Roll back the last migrations in reverse order", func(t *testing.T) {
+		testDB := setupMigrationTestDB(t)
+
+		mgr := NewManager(testDB, "sqlite")
+		for _, m := range GetAll() {
+			mgr.Register(m)
+		}
+		require.NoError(t, mgr.Up())
+		require.True(t, tableExists(t, testDB, "activities"))
+
+		require.NoError(t, Rollback(testDB, 2))
+
+		assert.False(t, tableExists(t, testDB, "activities"))
+		assert.False(t, tableExists(t, testDB, "sessions"))
+		assert.True(t, tableExists(t, testDB, "users_meta"))
+
+		var count int
+		require.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count))
+		assert.Equal(t, len(GetAll())-2, count)
+	})
+
+	t.Run("Steps larger than applied migrations", func(t *testing.T) {
+		testDB := setupMigrationTestDB(t)
+
+		require.NoError(t, Rollback(testDB, 1))
+
+		mgr := NewManager(testDB, "sqlite")
+		for _, m := range GetAll() {
+			mgr.Register(m)
+		}
+		require.NoError(t, mgr.Up())
+		require.NoError(t, mgr.Rollback(100))
+
+		assert.False(t, tableExists(t, testDB, "options"))
+	})
+
+	t.Run("Invalid steps", func(t *testing.T) {
+		testDB := setupMigrationTestDB(t)
+
+		assert.Error(t, Rollback(testDB, 0))
+	})
+
+	t.Run("Unknown applied migration", func(t *testing.T) {
+		testDB := setupMigrationTestDB(t)
+
+		mgr := NewManager(testDB, "sqlite")
+		mgr.Register(Migration{
+			Version: "1",
+			Up:      func(Executor) error { return nil },
+		})
+		require.NoError(t, mgr.Up())
+
+		assert.Error(t, Rollback(testDB, 1))
+	})
+}
+
+func TestUnitRollbackFailureIsAtomic(t *testing.T) {
+	testDB := setupMigrationTestDB(t)
+
+	mgr := NewManager(testDB, "sqlite")
+	mgr.Register(Migration{
+		Version: "1",
+		Up: func(db Executor) error {
+			if _, err := db.Exec("CREATE TABLE first (id INTEGER)"); err != nil {
+				return err
+			}
+			_, err := db.Exec("CREATE TABLE second (id INTEGER)")
+			return err
+		},
+		Down: func(db Executor) error {
+			if _, err := db.Exec("DROP TABLE second"); err != nil {
+				return err
+			}
+			// Fails after the first statement of the rollback
+			_, err := db.Exec("DROP TABLE missing")
+			return err
+		},
+	})
+	require.NoError(t, mgr.Up())
+
+	assert.Error(t, mgr.Rollback(1))
+
+	assert.True(t, tableExists(t, testDB, "first"))
+	assert.True(t, tableExists(t, testDB, "second"))
+
+	var count int
+	require.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM migrations WHERE version = '1'").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestUnitUpFailureIsAtomic(t *testing.T) {
+	testDB := setupMigrationTestDB(t)
+
+	mgr := NewManager(testDB, "sqlite")
+	mgr.Register(Migration{
+		Version: "1",
+		Up: func(db Executor) error {
+			if _, err := db.Exec("CREATE TABLE first (id INTEGER)"); err != nil {
+				return err
+			}
+			_, err := db.Exec("CREATE TABLE first (id INTEGER)")
+			return err
+		},
+	})
+
+	assert.Error(t, mgr.Up())
+
+	assert.False(t, tableExists(t, testDB, "first"))
+
+	var count int
+	require.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestUnitStatus(t *testing.T) {
+	testDB := setupMigrationTestDB(t)
+
+	statuses, err := Status(testDB)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(GetAll()))
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+		assert.Nil(t, status.AppliedAt)
+	}
+
+	mgr := NewManager(testDB, "sqlite")
+	for _, m := range GetAll() {
+		mgr.Register(m)
+	}
+	require.NoError(t, mgr.Up())
+	require.NoError(t, mgr.Down())
+
+	statuses, err = Status(testDB)
+	require.NoError(t, err)
+
+	last := len(statuses) - 1
+	for i, status := range statuses {
+		if i == last {
+			assert.False(t, status.Applied)
+			assert.Nil(t, status.AppliedAt)
+			continue
+		}
+		assert.True(t, status.Applied)
+		require.NotNil(t, status.AppliedAt)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+	assert.Equal(t, "20250101000003", statuses[0].Version)
+	assert.Equal(t, "Create options table", statuses[0].Description)
+}
@@ -10,24 +10,23 @@ import (
 	"strings"
 )
 
-// detectDriver attempts to determine the database driver type
-func detectDriver(db *sql.DB) string {
-	// Check SQLite
-	_, err := db.Exec("SELECT sqlite_version()")
-	if err == nil {
-		return "sqlite"
-	}
-
+// detectDriver attempts to determine the database driver type. PostgreSQL
+// is probed first because a failed statement aborts a PostgreSQL transaction,
+// while SQLite carries on.
+func detectDriver(db Executor) string {
 	// Check PostgreSQL
-	_, err = db.Exec("SELECT version()")
-	if err == nil {
-		var version string
-		db.QueryRow("SELECT version()").Scan(&version)
+	var version string
+	if err := db.QueryRow("SELECT version()").Scan(&version); err == nil {
 		if strings.Contains(strings.ToLower(version), "postgresql") {
 			return "postgres"
 		}
 	}
 
+	// Check SQLite
+	if _, err := db.Exec("SELECT sqlite_version()"); err == nil {
+		return "sqlite"
+	}
+
 	// Unknown database driver
 	return "unknown"
 }
@@ -68,8 +67,26 @@ func GetAll() []Migration {
 	}
 }
 
+// Rollback rolls back the last steps applied migrations of the registered migrations
+func Rollback(db *sql.DB, steps int) error {
+	mgr := NewManager(db, detectDriver(db))
+	for _, m := range GetAll() {
+		mgr.Register(m)
+	}
+	return mgr.Rollback(steps)
+}
+
+// Status returns the status of the registered migrations
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	mgr := NewManager(db, detectDriver(db))
+	for _, m := range GetAll() {
+		mgr.Register(m)
+	}
+	return mgr.Status()
+}
+
 // createOptionsTable creates the options table
-func createOptionsTable(db *sql.DB) error {
+func createOptionsTable(db Executor) error {
 	driver := detectDriver(db)
 	var query string
 
@@ -102,13 +119,13 @@ func createOptionsTable(db *sql.DB) error {
 }
 
 // dropOptionsTable drops the options table
-func dropOptionsTable(db *sql.DB) error {
+func dropOptionsTable(db Executor) error {
 	_, err := db.Exec("DROP TABLE IF EXISTS options")
 	return err
 }
 
 // createUsersTable creates the users table
-func createUsersTable(db *sql.DB) error {
+func createUsersTable(db Executor) error {
 	driver := detectDriver(db)
 	var query string
 
@@ -151,13 +168,13 @@ func createUsersTable(db *sql.DB) error {
 }
 
 // dropUsersTable drops the users table
-func dropUsersTable(db *sql.DB) error {
+func dropUsersTable(db Executor) error {
 	_, err := db.Exec("DROP TABLE IF EXISTS users")
 	return err
 }
 
 // createUsersMetaTable creates the users_meta table
-func createUsersMetaTable(db *sql.DB) error {
+func createUsersMetaTable(db Executor) error {
 	driver := detectDriver(db)
 	var query string
 
@@ -197,13 +214,13 @@ func createUsersMetaTable(db *sql.DB) error {
 }
 
 // dropUsersMetaTable drops the users_meta table
-func dropUsersMetaTable(db *sql.DB) error {
+func dropUsersMetaTable(db Executor) error {
 	_, err := db.Exec("DROP TABLE IF EXISTS users_meta")
 	return err
 }
 
 // createSessionsTable creates the sessions table
-func createSessionsTable(db *sql.DB) error {
+func createSessionsTable(db Executor) error {
 	driver := detectDriver(db)
 	var query string
 
@@ -246,13 +263,13 @@ func createSessionsTable(db *sql.DB) error {
 }
 
 // dropSessionsTable drops the sessions table
-func dropSessionsTable(db *sql.DB) error {
+func dropSessionsTable(db Executor) error {
 	_, err := db.Exec("DROP TABLE IF EXISTS sessions")
 	return err
 }
 
 // createActivitiesTable creates the activities table
-func createActivitiesTable(db *sql.DB) error {
+func createActivitiesTable(db Executor) error {
 	driver := detectDriver(db)
 	var query string
 
@@ -300,7 +317,7 @@ func createActivitiesTable(db *sql.DB) error {
 }
 
 // dropActivitiesTable drops the activities table
-func dropActivitiesTable(db *sql.DB) error {
+func dropActivitiesTable(db Executor) error {
 	_, err := db.Exec("DROP TABLE IF EXISTS activities")
 	return err
 }
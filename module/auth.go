@@ -7,35 +7,188 @@ package module
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/clivern/tut/db"
 	"github.com/clivern/tut/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+// dummyPasswordHash returns a hash with the configured cost to compare
+// against when the user does not exist
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = service.HashPassword(uuid.New().String())
+	})
+	return dummyHash
+}
+
+// Failed login metadata
+const (
+	// UserMetaFailedLoginAttempts is the meta key counting the recent failed logins
+	UserMetaFailedLoginAttempts = "failed_login_attempts"
+	// UserMetaFailedLoginAt is the meta key holding the time of the first failed
+	// login of the current window
+	UserMetaFailedLoginAt = "failed_login_at"
+	// DefaultMaxFailedLogins is the failed logins that lock an account
+	DefaultMaxFailedLogins = 5
+	// DefaultLockoutDuration is the window failed logins are counted in, an
+	// account stays locked until the window of its first failure ends
+	DefaultLockoutDuration = 15 * time.Minute
+)
+
+// Auth module errors
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// AccountLockedError is returned by Login while an account is locked
+type AccountLockedError struct {
+	Until time.Time
+}
+
+// Error returns the error message
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account is locked until %s", e.Until.Format(time.RFC3339))
+}
+
 // Auth is a module that handles authentication.
 type Auth struct {
-	UserRepository *db.UserRepository
+	UserRepository     *db.UserRepository
+	UserMetaRepository *db.UserMetaRepository
+	// MaxFailedLogins locks an account after that many failed logins, zero disables the lockout
+	MaxFailedLogins int
+	LockoutDuration time.Duration
 }
 
 // NewAuth creates a new auth.
-func NewAuth(repo *db.UserRepository) *Auth {
-	return &Auth{UserRepository: repo}
+func NewAuth(repo *db.UserRepository, metaRepo *db.UserMetaRepository) *Auth {
+	return &Auth{
+		UserRepository:     repo,
+		UserMetaRepository: metaRepo,
+		MaxFailedLogins:    DefaultMaxFailedLogins,
+		LockoutDuration:    DefaultLockoutDuration,
+	}
 }
 
-// Login authenticates a user.
+// Login authenticates a user. It returns an AccountLockedError without
+// checking the password once the account had too many failed logins.
 func (a *Auth) Login(email, password string) (*db.User, error) {
 	user, err := a.UserRepository.GetByEmail(email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("email not found")
+		// Spend the same time as a real password check so response
+		// times do not reveal which emails are registered
+		service.ComparePassword(dummyPasswordHash(), password)
+		return nil, ErrInvalidCredentials
+	}
+
+	// Accounts managed by single sign-on never log in with a password
+	ssoManaged, err := NewUser(a.UserRepository, a.UserMetaRepository).IsSSOManaged(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ssoManaged {
+		service.ComparePassword(dummyPasswordHash(), password)
+		return nil, ErrInvalidCredentials
+	}
+
+	attempts, firstFailure, firstFailureValue, err := a.failedLogins(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	lockedUntil := firstFailure.Add(a.LockoutDuration)
+	if a.MaxFailedLogins > 0 && attempts >= a.MaxFailedLogins && now.Before(lockedUntil) {
+		return nil, &AccountLockedError{Until: lockedUntil}
 	}
+
 	if !service.ComparePassword(user.Password, password) {
-		return nil, errors.New("invalid password")
+		if a.MaxFailedLogins > 0 {
+			if err := a.recordFailedLogin(user.ID, attempts, firstFailure, firstFailureValue, now); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if attempts > 0 || firstFailureValue != "" {
+		if err := a.UserMetaRepository.Delete(user.ID, UserMetaFailedLoginAttempts); err != nil {
+			return nil, err
+		}
+		if err := a.UserMetaRepository.Delete(user.ID, UserMetaFailedLoginAt); err != nil {
+			return nil, err
+		}
 	}
 
-	a.UserRepository.UpdateLastLogin(user.ID)
+	if err := a.UserRepository.UpdateLastLogin(user.ID); err != nil {
+		log.Error().Err(err).Int64("userID", user.ID).Msg("Failed to update last login")
+	}
 
 	return user, nil
 }
+
+// recordFailedLogin counts a failed login. Every change is a single statement
+// so concurrent failures are never lost, and failed_login_at keeps the time of
+// the first failure of the current window.
+func (a *Auth) recordFailedLogin(userID int64, attempts int, firstFailure time.Time, firstFailureValue string, now time.Time) error {
+	// Only one of the concurrent logins that find an expired window removes
+	// it, and takes the failures it counted off the counter
+	if firstFailureValue != "" && !now.Before(firstFailure.Add(a.LockoutDuration)) {
+		expired, err := a.UserMetaRepository.DeleteIfValue(userID, UserMetaFailedLoginAt, firstFailureValue)
+		if err != nil {
+			return err
+		}
+		if expired && attempts > 0 {
+			if _, err := a.UserMetaRepository.Increment(userID, UserMetaFailedLoginAttempts, -attempts); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := a.UserMetaRepository.Increment(userID, UserMetaFailedLoginAttempts, 1); err != nil {
+		return err
+	}
+
+	return a.UserMetaRepository.CreateIfMissing(userID, UserMetaFailedLoginAt, now.Format(time.RFC3339))
+}
+
+// failedLogins returns the failed logins of a user in the current window, the
+// time of the first one and its stored value
+func (a *Auth) failedLogins(userID int64) (int, time.Time, string, error) {
+	attemptsMeta, err := a.UserMetaRepository.Get(userID, UserMetaFailedLoginAttempts)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+
+	atMeta, err := a.UserMetaRepository.Get(userID, UserMetaFailedLoginAt)
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+
+	attempts := 0
+	if attemptsMeta != nil {
+		attempts, _ = strconv.Atoi(attemptsMeta.Value)
+	}
+
+	if atMeta == nil {
+		return attempts, time.Time{}, "", nil
+	}
+
+	// An unreadable time leaves the zero time, so the window counts as expired
+	firstFailure, _ := time.Parse(time.RFC3339, atMeta.Value)
+
+	return attempts, firstFailure, atMeta.Value, nil
+}
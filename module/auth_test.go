@@ -0,0 +1,171 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/migration"
+	"github.com/clivern/tut/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestUnitAuthLockout tests locking an account after too many failed logins
+func TestUnitAuthLockout(t *testing.T) {
+	require.NoError(t, service.SetBcryptCost(bcrypt.MinCost))
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	metaRepo := db.NewUserMetaRepository(testDB)
+
+	hashedPassword, err := service.HashPassword("Secret123!")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(&db.User{Email: "lock@example.com", Password: hashedPassword, Role: db.UserRoleUser, IsActive: true}))
+
+	auth := NewAuth(userRepo, metaRepo)
+	auth.MaxFailedLogins = 3
+
+	t.Run("Successful login resets the failures", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := auth.Login("lock@example.com", "wrong")
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := auth.Login("lock@example.com", "Secret123!")
+		require.NoError(t, err)
+
+		user, err := userRepo.GetByEmail("lock@example.com")
+		require.NoError(t, err)
+		meta, err := metaRepo.Get(user.ID, UserMetaFailedLoginAttempts)
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+	})
+
+	t.Run("Account locks after the failures", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := auth.Login("lock@example.com", "wrong")
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		// Even the right password is rejected while locked
+		_, err := auth.Login("lock@example.com", "Secret123!")
+
+		var lockedErr *AccountLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.WithinDuration(t, time.Now().Add(DefaultLockoutDuration), lockedErr.Until, time.Minute)
+	})
+
+	t.Run("The first failure starts the window", func(t *testing.T) {
+		_, err := auth.Login("lock@example.com", "Secret123!")
+		var lockedErr *AccountLockedError
+		require.ErrorAs(t, err, &lockedErr)
+
+		user, err := userRepo.GetByEmail("lock@example.com")
+		require.NoError(t, err)
+		firstFailure, err := metaRepo.Get(user.ID, UserMetaFailedLoginAt)
+		require.NoError(t, err)
+		assert.Equal(t, lockedErr.Until.Add(-DefaultLockoutDuration).Format(time.RFC3339), firstFailure.Value)
+	})
+
+	t.Run("Lock expires", func(t *testing.T) {
+		user, err := userRepo.GetByEmail("lock@example.com")
+		require.NoError(t, err)
+		require.NoError(t, metaRepo.Update(user.ID, UserMetaFailedLoginAt, time.Now().UTC().Add(-DefaultLockoutDuration-time.Minute).Format(time.RFC3339)))
+
+		_, err = auth.Login("lock@example.com", "Secret123!")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failures after an expired window start a new one", func(t *testing.T) {
+		user, err := userRepo.GetByEmail("lock@example.com")
+		require.NoError(t, err)
+		expired := time.Now().UTC().Add(-DefaultLockoutDuration - time.Minute).Format(time.RFC3339)
+		require.NoError(t, metaRepo.SetMultiple(user.ID, map[string]string{
+			UserMetaFailedLoginAttempts: "2",
+			UserMetaFailedLoginAt:       expired,
+		}))
+
+		_, err = auth.Login("lock@example.com", "wrong")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		attempts, err := metaRepo.Get(user.ID, UserMetaFailedLoginAttempts)
+		require.NoError(t, err)
+		assert.Equal(t, "1", attempts.Value)
+		firstFailure, err := metaRepo.Get(user.ID, UserMetaFailedLoginAt)
+		require.NoError(t, err)
+		assert.NotEqual(t, expired, firstFailure.Value)
+	})
+
+	t.Run("Unknown emails are invalid credentials", func(t *testing.T) {
+		_, err := auth.Login("missing@example.com", "Secret123!")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
+
+// TestIntegrationAuthConcurrentFailures tests that concurrent failed logins are all counted
+func TestIntegrationAuthConcurrentFailures(t *testing.T) {
+	require.NoError(t, service.SetBcryptCost(bcrypt.MinCost))
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	conn, err := db.NewConnection(db.Config{
+		Driver:     "sqlite",
+		DataSource: filepath.Join(t.TempDir(), "tut.db"),
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mgr := migration.NewManager(conn.DB, "sqlite")
+	for _, m := range migration.GetAll() {
+		mgr.Register(m)
+	}
+	require.NoError(t, mgr.Up())
+
+	userRepo := db.NewUserRepository(conn.DB)
+	metaRepo := db.NewUserMetaRepository(conn.DB)
+
+	hashedPassword, err := service.HashPassword("Secret123!")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(&db.User{Email: "race@example.com", Password: hashedPassword, Role: db.UserRoleUser, IsActive: true}))
+
+	auth := NewAuth(userRepo, metaRepo)
+	auth.MaxFailedLogins = 100
+
+	const logins = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, logins)
+	for i := 0; i < logins; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := auth.Login("race@example.com", "wrong")
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	user, err := userRepo.GetByEmail("race@example.com")
+	require.NoError(t, err)
+	attempts, err := metaRepo.Get(user.ID, UserMetaFailedLoginAttempts)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(logins), attempts.Value)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"sync"
+	"time"
+
+	"github.com/clivern/tut/db"
+)
+
+// DashboardSummary holds the instance wide numbers of the admin dashboard
+type DashboardSummary struct {
+	TotalUsers     int64
+	ActiveUsers    int64
+	InactiveUsers  int64
+	UploadsLast24h int64
+	UploadsLast7d  int64
+	ComputedAt     time.Time
+}
+
+// dashboardCache keeps the last computed summary between requests
+var dashboardCache struct {
+	mu      sync.Mutex
+	summary *DashboardSummary
+}
+
+// Dashboard computes the admin dashboard summary
+type Dashboard struct {
+	UserRepository     *db.UserRepository
+	ActivityRepository *db.ActivityRepository
+}
+
+// NewDashboard creates a new dashboard module instance
+func NewDashboard(userRepo *db.UserRepository, activityRepo *db.ActivityRepository) *Dashboard {
+	return &Dashboard{UserRepository: userRepo, ActivityRepository: activityRepo}
+}
+
+// GetSummary returns the dashboard summary, reusing the last computed one
+// while it is younger than the ttl
+func (d *Dashboard) GetSummary(ttl time.Duration) (*DashboardSummary, error) {
+	dashboardCache.mu.Lock()
+	defer dashboardCache.mu.Unlock()
+
+	cached := dashboardCache.summary
+	if cached != nil && ttl > 0 && time.Since(cached.ComputedAt) < ttl {
+		return cached, nil
+	}
+
+	summary, err := d.computeSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardCache.summary = summary
+	return summary, nil
+}
+
+// computeSummary runs the aggregate queries of the summary
+func (d *Dashboard) computeSummary() (*DashboardSummary, error) {
+	now := time.Now().UTC()
+
+	active, inactive, err := d.UserRepository.CountByStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	lastDay, err := d.ActivityRepository.Summarize(now.Add(-24*time.Hour), now)
+	if err != nil {
+		return nil, err
+	}
+
+	lastWeek, err := d.ActivityRepository.Summarize(now.AddDate(0, 0, -7), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DashboardSummary{
+		TotalUsers:     active + inactive,
+		ActiveUsers:    active,
+		InactiveUsers:  inactive,
+		UploadsLast24h: lastDay.TotalUploads,
+		UploadsLast7d:  lastWeek.TotalUploads,
+		ComputedAt:     now,
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitDashboard_GetSummary(t *testing.T) {
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	_, err := testDB.Exec(`
+		CREATE TABLE activities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			user_email VARCHAR(255),
+			action VARCHAR(100) NOT NULL,
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id INTEGER,
+			details TEXT,
+			ip_address VARCHAR(45),
+			user_agent VARCHAR(500),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`INSERT INTO users (email, password, role, is_active) VALUES
+		('a@example.com', 'x', 'user', 1),
+		('b@example.com', 'x', 'user', 1),
+		('c@example.com', 'x', 'user', 0)`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	for _, createdAt := range []time.Time{now.Add(-time.Hour), now.Add(-48 * time.Hour), now.AddDate(0, 0, -30)} {
+		_, err = testDB.Exec(
+			`INSERT INTO activities (user_id, action, entity_type, created_at) VALUES (1, ?, 'file', ?)`,
+			db.ActivityActionFileUploaded,
+			createdAt.Format("2006-01-02 15:04:05"),
+		)
+		require.NoError(t, err)
+	}
+
+	dashboard := NewDashboard(db.NewUserRepository(testDB), db.NewActivityRepository(testDB))
+
+	summary, err := dashboard.GetSummary(0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), summary.TotalUsers)
+	assert.Equal(t, int64(2), summary.ActiveUsers)
+	assert.Equal(t, int64(1), summary.InactiveUsers)
+	assert.Equal(t, int64(1), summary.UploadsLast24h)
+	assert.Equal(t, int64(2), summary.UploadsLast7d)
+
+	t.Run("Cached summary is reused within the ttl", func(t *testing.T) {
+		_, err := testDB.Exec(`INSERT INTO users (email, password, role) VALUES ('d@example.com', 'x', 'user')`)
+		require.NoError(t, err)
+
+		cached, err := dashboard.GetSummary(time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, summary, cached)
+
+		fresh, err := dashboard.GetSummary(0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), fresh.TotalUsers)
+	})
+}
@@ -0,0 +1,287 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// Single sign-on user metadata
+const (
+	// UserMetaAuthProvider is the meta key holding the provider that manages an account
+	UserMetaAuthProvider = "auth_provider"
+	// UserMetaOIDCSubject is the meta key holding the linked issuer and subject
+	UserMetaOIDCSubject = "oidc_subject"
+	// AuthProviderOIDC marks accounts managed by the OpenID Connect provider
+	AuthProviderOIDC = "oidc"
+)
+
+// OIDC module errors
+var (
+	ErrOIDCDisabled         = errors.New("single sign-on is not enabled")
+	ErrOIDCEmailNotVerified = errors.New("identity provider email is not verified")
+	ErrOIDCMissingEmail     = errors.New("identity provider did not return an email")
+	ErrOIDCAccountPending   = errors.New("account with this email has not been verified")
+)
+
+var (
+	// providers caches discovered providers by issuer URL
+	providers = map[string]*oidc.Provider{}
+	// providersMu protects providers
+	providersMu sync.Mutex
+)
+
+// OIDC handles OpenID Connect single sign-on.
+type OIDC struct {
+	UserRepository     *db.UserRepository
+	UserMetaRepository *db.UserMetaRepository
+	Settings           *OIDCSettings
+}
+
+// OIDCIdentity contains the verified claims of an ID token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// NewOIDC creates a new OIDC module instance.
+func NewOIDC(userRepo *db.UserRepository, userMetaRepo *db.UserMetaRepository, settings *OIDCSettings) *OIDC {
+	return &OIDC{
+		UserRepository:     userRepo,
+		UserMetaRepository: userMetaRepo,
+		Settings:           settings,
+	}
+}
+
+// AuthCodeURL returns the identity provider URL to redirect the user to.
+func (o *OIDC) AuthCodeURL(state, nonce, verifier string) (string, error) {
+	config, _, err := o.clients()
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(
+		state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(verifier),
+	), nil
+}
+
+// Exchange trades the authorization code for tokens and verifies the ID token.
+func (o *OIDC) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	config, verifierFn, err := o.clients()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("token response is missing the id_token")
+	}
+
+	idToken, err := verifierFn.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+
+	return &OIDCIdentity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+	}, nil
+}
+
+// ResolveUser finds the user linked to an identity, links an existing
+// user by verified email or provisions a new one.
+func (o *OIDC) ResolveUser(identity *OIDCIdentity) (*db.User, error) {
+	subject := fmt.Sprintf("%s|%s", o.Settings.IssuerURL, identity.Subject)
+
+	meta, err := o.UserMetaRepository.GetByKeyValue(UserMetaOIDCSubject, subject)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		user, err := o.UserRepository.GetByID(meta.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			return user, nil
+		}
+	}
+
+	if identity.Email == "" {
+		return nil, ErrOIDCMissingEmail
+	}
+	if !identity.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := o.UserRepository.GetByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		user, err = o.provisionUser(identity.Email)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := o.linkUser(user); err != nil {
+		return nil, err
+	}
+
+	if err := o.UserMetaRepository.Upsert(user.ID, UserMetaAuthProvider, AuthProviderOIDC); err != nil {
+		return nil, err
+	}
+	if err := o.UserMetaRepository.Upsert(user.ID, UserMetaOIDCSubject, subject); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// linkUser takes over an existing account for single sign-on. Anyone could
+// have registered the email, so an account still pending verification is
+// refused, and the password and verification token of a newly linked account
+// are discarded so only the identity provider can sign in to it.
+func (o *OIDC) linkUser(user *db.User) error {
+	userModule := NewUser(o.UserRepository, o.UserMetaRepository)
+
+	ssoManaged, err := userModule.IsSSOManaged(user.ID)
+	if err != nil || ssoManaged {
+		return err
+	}
+
+	pending, err := userModule.IsPendingVerification(user.ID)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return ErrOIDCAccountPending
+	}
+
+	user.Password, err = unusablePasswordHash()
+	if err != nil {
+		return err
+	}
+	if err := o.UserRepository.Update(user); err != nil {
+		return err
+	}
+
+	if err := o.UserMetaRepository.Delete(user.ID, UserMetaVerificationToken); err != nil {
+		return err
+	}
+	return o.UserMetaRepository.Delete(user.ID, UserMetaVerificationExpiresAt)
+}
+
+// provisionUser creates a user for a first time single sign-on login
+func (o *OIDC) provisionUser(email string) (*db.User, error) {
+	hashedPassword, err := unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &db.User{
+		Email:       email,
+		Password:    hashedPassword,
+		Role:        o.Settings.DefaultRole,
+		APIKey:      uuid.New().String(),
+		IsActive:    true,
+		LastLoginAt: time.Now().UTC(),
+	}
+
+	if err := o.UserRepository.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// unusablePasswordHash hashes an unguessable password, SSO users never log
+// in with a password
+func unusablePasswordHash() (string, error) {
+	secret, err := generateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	return service.HashPassword(secret)
+}
+
+// clients builds the OAuth2 config and ID token verifier for the configured provider
+func (o *OIDC) clients() (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	if !o.Settings.Enabled {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	provider, err := getProvider(o.Settings.IssuerURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := &oauth2.Config{
+		ClientID:     o.Settings.ClientID,
+		ClientSecret: o.Settings.ClientSecret,
+		RedirectURL:  o.Settings.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+
+	verifier := provider.Verifier(&oidc.Config{ClientID: o.Settings.ClientID})
+
+	return config, verifier, nil
+}
+
+// getProvider discovers the provider once per issuer and caches it
+func getProvider(issuerURL string) (*oidc.Provider, error) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if provider, ok := providers[issuerURL]; ok {
+		return provider, nil
+	}
+
+	// The provider keeps using this context to refresh signing keys,
+	// so it must outlive the request that triggered the discovery
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: 10 * time.Second})
+
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider [%s]: %w", issuerURL, err)
+	}
+
+	providers[issuerURL] = provider
+	return provider, nil
+}
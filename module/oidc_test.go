@@ -0,0 +1,238 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupOIDCModuleTestDB(t *testing.T) *sql.DB {
+	testDB, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	testDB.SetMaxOpenConns(1)
+
+	_, err = testDB.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			password VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL DEFAULT 'user',
+			api_key VARCHAR(255) UNIQUE,
+			is_active BOOLEAN DEFAULT 1,
+			last_login_at DATETIME NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`
+		CREATE TABLE users_meta (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL,
+			value TEXT,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, key)
+		)
+	`)
+	require.NoError(t, err)
+
+	return testDB
+}
+
+func TestUnitOIDC_ResolveUser(t *testing.T) {
+	settings := &OIDCSettings{
+		Enabled:     true,
+		IssuerURL:   "https://idp.example.com",
+		DefaultRole: db.UserRoleReadonly,
+	}
+
+	t.Run("Provision a new user", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		userRepo := db.NewUserRepository(testDB)
+		metaRepo := db.NewUserMetaRepository(testDB)
+		oidcModule := NewOIDC(userRepo, metaRepo, settings)
+
+		user, err := oidcModule.ResolveUser(&OIDCIdentity{
+			Subject:       "abc",
+			Email:         "new@example.com",
+			EmailVerified: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.Equal(t, db.UserRoleReadonly, user.Role)
+		assert.True(t, user.IsActive)
+
+		ssoManaged, err := NewUser(userRepo, metaRepo).IsSSOManaged(user.ID)
+		assert.NoError(t, err)
+		assert.True(t, ssoManaged)
+	})
+
+	t.Run("Link an existing user by verified email", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		userRepo := db.NewUserRepository(testDB)
+		metaRepo := db.NewUserMetaRepository(testDB)
+		oidcModule := NewOIDC(userRepo, metaRepo, settings)
+
+		existing := &db.User{
+			Email:    "local@example.com",
+			Password: "hashedpassword",
+			Role:     db.UserRoleAdmin,
+			APIKey:   "key-1",
+			IsActive: true,
+		}
+		require.NoError(t, userRepo.Create(existing))
+
+		user, err := oidcModule.ResolveUser(&OIDCIdentity{
+			Subject:       "def",
+			Email:         "local@example.com",
+			EmailVerified: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, user.ID)
+		assert.Equal(t, db.UserRoleAdmin, user.Role)
+
+		// Subsequent logins resolve by subject even if the email changes
+		user, err = oidcModule.ResolveUser(&OIDCIdentity{
+			Subject: "def",
+			Email:   "renamed@example.com",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, user.ID)
+	})
+
+	t.Run("Reject unverified email", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		oidcModule := NewOIDC(db.NewUserRepository(testDB), db.NewUserMetaRepository(testDB), settings)
+
+		user, err := oidcModule.ResolveUser(&OIDCIdentity{
+			Subject: "ghi",
+			Email:   "unverified@example.com",
+		})
+		assert.ErrorIs(t, err, ErrOIDCEmailNotVerified)
+		assert.Nil(t, user)
+	})
+
+	t.Run("Reject missing email", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		oidcModule := NewOIDC(db.NewUserRepository(testDB), db.NewUserMetaRepository(testDB), settings)
+
+		user, err := oidcModule.ResolveUser(&OIDCIdentity{Subject: "jkl"})
+		assert.ErrorIs(t, err, ErrOIDCMissingEmail)
+		assert.Nil(t, user)
+	})
+}
+
+// TestUnitOIDC_PreRegisteredEmail tests that an account registered by someone
+// else under the email of an SSO user can not be used with its password
+func TestUnitOIDC_PreRegisteredEmail(t *testing.T) {
+	require.NoError(t, service.SetBcryptCost(bcrypt.MinCost))
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	metaRepo := db.NewUserMetaRepository(testDB)
+	oidcModule := NewOIDC(userRepo, metaRepo, &OIDCSettings{
+		Enabled:     true,
+		IssuerURL:   "https://idp.example.com",
+		DefaultRole: db.UserRoleUser,
+	})
+	identity := &OIDCIdentity{Subject: "victim", Email: "victim@example.com", EmailVerified: true}
+
+	registered, _, err := NewRegistration(userRepo, metaRepo, &RegistrationSettings{Enabled: true}).
+		Register("victim@example.com", "Attacker123!")
+	require.NoError(t, err)
+
+	t.Run("Pending accounts are not linked", func(t *testing.T) {
+		user, err := oidcModule.ResolveUser(identity)
+		assert.ErrorIs(t, err, ErrOIDCAccountPending)
+		assert.Nil(t, user)
+
+		ssoManaged, err := NewUser(userRepo, metaRepo).IsSSOManaged(registered.ID)
+		require.NoError(t, err)
+		assert.False(t, ssoManaged)
+	})
+
+	t.Run("Linking discards the password", func(t *testing.T) {
+		_, err := NewUser(userRepo, metaRepo).ActivateUser(registered.ID)
+		require.NoError(t, err)
+
+		user, err := oidcModule.ResolveUser(identity)
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, user.ID)
+
+		_, err = NewAuth(userRepo, metaRepo).Login("victim@example.com", "Attacker123!")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		pending, err := NewUser(userRepo, metaRepo).IsPendingVerification(registered.ID)
+		require.NoError(t, err)
+		assert.False(t, pending)
+	})
+}
+
+func TestUnitOIDC_Disabled(t *testing.T) {
+	oidcModule := NewOIDC(nil, nil, &OIDCSettings{Enabled: false})
+
+	url, err := oidcModule.AuthCodeURL("state", "nonce", "verifier")
+	assert.ErrorIs(t, err, ErrOIDCDisabled)
+	assert.Empty(t, url)
+}
+
+func TestUnitUser_UpdateSSOManagedPassword(t *testing.T) {
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	metaRepo := db.NewUserMetaRepository(testDB)
+	userModule := NewUser(userRepo, metaRepo)
+
+	user := &db.User{
+		Email:    "sso@example.com",
+		Password: "hashedpassword",
+		Role:     db.UserRoleUser,
+		APIKey:   "key-2",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+	require.NoError(t, metaRepo.Create(user.ID, UserMetaAuthProvider, AuthProviderOIDC))
+
+	_, err := userModule.UpdateUser(&UpdateUserOptions{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Password: "NewPassword123!",
+		Role:     user.Role,
+		IsActive: true,
+	})
+	assert.ErrorIs(t, err, ErrUserSSOManaged)
+
+	updated, err := userModule.UpdateUser(&UpdateUserOptions{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Role:     db.UserRoleReadonly,
+		IsActive: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, db.UserRoleReadonly, updated.Role)
+}
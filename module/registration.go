@@ -0,0 +1,158 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/google/uuid"
+)
+
+// Email verification metadata
+const (
+	// UserMetaVerificationToken is the meta key holding the hashed verification token
+	UserMetaVerificationToken = "email_verification_token"
+	// UserMetaVerificationExpiresAt is the meta key holding the token expiry time
+	UserMetaVerificationExpiresAt = "email_verification_expires_at"
+	// VerificationTokenTTL is how long a verification link stays valid
+	VerificationTokenTTL = 24 * time.Hour
+)
+
+// Registration module errors
+var (
+	ErrRegistrationDisabled        = errors.New("registration is disabled")
+	ErrRegistrationEmailNotAllowed = errors.New("email domain is not allowed")
+	ErrVerificationTokenInvalid    = errors.New("verification token is invalid or expired")
+)
+
+// Registration handles user self-registration and email verification.
+type Registration struct {
+	UserRepository     *db.UserRepository
+	UserMetaRepository *db.UserMetaRepository
+	Settings           *RegistrationSettings
+}
+
+// NewRegistration creates a new registration module instance.
+func NewRegistration(userRepo *db.UserRepository, userMetaRepo *db.UserMetaRepository, settings *RegistrationSettings) *Registration {
+	return &Registration{
+		UserRepository:     userRepo,
+		UserMetaRepository: userMetaRepo,
+		Settings:           settings,
+	}
+}
+
+// Register creates an inactive user and returns the plain verification token
+// to be sent by email. Only a hash of the token is stored. The password is
+// hashed by the caller so the bcrypt cost is not paid inside a transaction.
+func (r *Registration) Register(email, hashedPassword string) (*db.User, string, error) {
+	if !r.Settings.Enabled {
+		return nil, "", ErrRegistrationDisabled
+	}
+
+	if r.isBlocked(email) {
+		return nil, "", ErrRegistrationEmailNotAllowed
+	}
+
+	existingUser, err := r.UserRepository.GetByEmail(email)
+	if err != nil {
+		return nil, "", err
+	}
+	if existingUser != nil {
+		return nil, "", ErrUserEmailAlreadyExists
+	}
+
+	user := &db.User{
+		Email:       email,
+		Password:    hashedPassword,
+		Role:        db.UserRoleUser,
+		APIKey:      uuid.New().String(),
+		IsActive:    false,
+		LastLoginAt: time.Time{},
+	}
+
+	if err := r.UserRepository.Create(user); err != nil {
+		return nil, "", err
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = r.UserMetaRepository.Upsert(user.ID, UserMetaVerificationToken, hashToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = r.UserMetaRepository.Upsert(
+		user.ID,
+		UserMetaVerificationExpiresAt,
+		time.Now().UTC().Add(VerificationTokenTTL).Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, token, nil
+}
+
+// VerifyEmail activates the user owning the verification token.
+func (r *Registration) VerifyEmail(token string) (*db.User, error) {
+	if token == "" {
+		return nil, ErrVerificationTokenInvalid
+	}
+
+	meta, err := r.UserMetaRepository.GetByKeyValue(UserMetaVerificationToken, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, ErrVerificationTokenInvalid
+	}
+
+	expiry, err := r.UserMetaRepository.Get(meta.UserID, UserMetaVerificationExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiry == nil {
+		return nil, ErrVerificationTokenInvalid
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, expiry.Value)
+	if err != nil || expiresAt.Before(time.Now().UTC()) {
+		return nil, ErrVerificationTokenInvalid
+	}
+
+	return NewUser(r.UserRepository, r.UserMetaRepository).ActivateUser(meta.UserID)
+}
+
+// isBlocked checks the email domain against the configured patterns
+func (r *Registration) isBlocked(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return true
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, pattern := range r.Settings.BlockedDomains {
+		if matched, _ := path.Match(strings.ToLower(pattern), domain); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashToken hashes a token before it is stored or looked up
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
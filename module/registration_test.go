@@ -0,0 +1,160 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitRegistration_Register(t *testing.T) {
+	t.Run("Registration disabled", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			db.NewUserMetaRepository(testDB),
+			&RegistrationSettings{Enabled: false},
+		)
+
+		_, _, err := registration.Register("user@example.com", "Password123!")
+		assert.ErrorIs(t, err, ErrRegistrationDisabled)
+	})
+
+	t.Run("Blocked domain", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			db.NewUserMetaRepository(testDB),
+			&RegistrationSettings{Enabled: true, BlockedDomains: []string{"*.tempmail.io"}},
+		)
+
+		_, _, err := registration.Register("user@box.TempMail.io", "Password123!")
+		assert.ErrorIs(t, err, ErrRegistrationEmailNotAllowed)
+	})
+
+	t.Run("Duplicate email", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			db.NewUserMetaRepository(testDB),
+			&RegistrationSettings{Enabled: true},
+		)
+
+		_, _, err := registration.Register("user@example.com", "Password123!")
+		require.NoError(t, err)
+
+		_, _, err = registration.Register("user@example.com", "Password123!")
+		assert.ErrorIs(t, err, ErrUserEmailAlreadyExists)
+	})
+
+	t.Run("Creates a pending user", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		metaRepo := db.NewUserMetaRepository(testDB)
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			metaRepo,
+			&RegistrationSettings{Enabled: true},
+		)
+
+		user, token, err := registration.Register("user@example.com", "Password123!")
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.False(t, user.IsActive)
+		assert.Equal(t, db.UserRoleUser, user.Role)
+
+		meta, err := metaRepo.Get(user.ID, UserMetaVerificationToken)
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+		assert.NotEqual(t, token, meta.Value)
+
+		pending, err := NewUser(db.NewUserRepository(testDB), metaRepo).IsPendingVerification(user.ID)
+		require.NoError(t, err)
+		assert.True(t, pending)
+	})
+}
+
+func TestUnitRegistration_VerifyEmail(t *testing.T) {
+	t.Run("Valid token activates the user", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		metaRepo := db.NewUserMetaRepository(testDB)
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			metaRepo,
+			&RegistrationSettings{Enabled: true},
+		)
+
+		user, token, err := registration.Register("user@example.com", "Password123!")
+		require.NoError(t, err)
+
+		verified, err := registration.VerifyEmail(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, verified.ID)
+		assert.True(t, verified.IsActive)
+
+		meta, err := metaRepo.Get(user.ID, UserMetaVerificationToken)
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+
+		// Tokens are single use
+		_, err = registration.VerifyEmail(token)
+		assert.ErrorIs(t, err, ErrVerificationTokenInvalid)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			db.NewUserMetaRepository(testDB),
+			&RegistrationSettings{Enabled: true},
+		)
+
+		_, err := registration.VerifyEmail("")
+		assert.ErrorIs(t, err, ErrVerificationTokenInvalid)
+
+		_, err = registration.VerifyEmail("unknown")
+		assert.ErrorIs(t, err, ErrVerificationTokenInvalid)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		testDB := setupOIDCModuleTestDB(t)
+		defer testDB.Close()
+
+		metaRepo := db.NewUserMetaRepository(testDB)
+		registration := NewRegistration(
+			db.NewUserRepository(testDB),
+			metaRepo,
+			&RegistrationSettings{Enabled: true},
+		)
+
+		user, token, err := registration.Register("user@example.com", "Password123!")
+		require.NoError(t, err)
+
+		err = metaRepo.Upsert(
+			user.ID,
+			UserMetaVerificationExpiresAt,
+			time.Now().UTC().Add(-time.Hour).Format(time.RFC3339),
+		)
+		require.NoError(t, err)
+
+		_, err = registration.VerifyEmail(token)
+		assert.ErrorIs(t, err, ErrVerificationTokenInvalid)
+	})
+}
@@ -0,0 +1,22 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"errors"
+
+	"github.com/clivern/tut/db"
+)
+
+// Configuration reload errors
+var (
+	ErrConfigInvalid   = errors.New("invalid configuration")
+	ErrConfigImmutable = errors.New("configuration change requires a restart")
+)
+
+// ConfigReloadFunc re-reads the configuration file, applies the settings that
+// can change at runtime and returns the changed keys. The activity describes
+// who asked for the reload and is nil when it was not a user.
+type ConfigReloadFunc func(activity *db.Activity) ([]string, error)
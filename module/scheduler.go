@@ -0,0 +1,201 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TaskFunc is a recurring task run by the scheduler
+type TaskFunc func(ctx context.Context) error
+
+// JobStatus describes a registered task and its last run
+type JobStatus struct {
+	Name         string
+	Interval     time.Duration
+	Runs         int64
+	LastRunAt    *time.Time
+	LastDuration time.Duration
+	LastError    string
+	NextRunAt    *time.Time
+}
+
+// job is a registered task with its run state
+type job struct {
+	name     string
+	interval time.Duration
+	task     TaskFunc
+	status   JobStatus
+}
+
+// Scheduler runs registered tasks at fixed intervals
+type Scheduler struct {
+	mu      sync.RWMutex
+	jobs    map[string]*job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+var (
+	defaultScheduler     *Scheduler
+	defaultSchedulerOnce sync.Once
+)
+
+// GetScheduler returns the application wide scheduler
+func GetScheduler() *Scheduler {
+	defaultSchedulerOnce.Do(func() {
+		defaultScheduler = NewScheduler()
+	})
+	return defaultScheduler
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*job)}
+}
+
+// Register adds a task that runs every interval once the scheduler is started
+func (s *Scheduler) Register(name string, interval time.Duration, task TaskFunc) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval of task %s must be greater than zero", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("task %s cannot be registered while the scheduler is running", name)
+	}
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("task %s is already registered", name)
+	}
+
+	s.jobs[name] = &job{
+		name:     name,
+		interval: interval,
+		task:     task,
+		status:   JobStatus{Name: name, Interval: interval},
+	}
+
+	return nil
+}
+
+// Start runs every registered task in its own goroutine until Stop is called
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.running = true
+
+	for _, j := range s.jobs {
+		next := time.Now().UTC().Add(j.interval)
+		j.status.NextRunAt = &next
+
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	log.Info().Int("tasks", len(s.jobs)).Msg("Scheduler started")
+}
+
+// Stop stops the scheduler and waits for running tasks to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.running = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	log.Info().Msg("Scheduler stopped")
+}
+
+// Jobs returns the status of the registered tasks ordered by name
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j.status)
+	}
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Name < jobs[k].Name
+	})
+
+	return jobs
+}
+
+// loop runs a task every interval until the context is canceled
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, j)
+		}
+	}
+}
+
+// run runs a task once and records its status
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	start := time.Now().UTC()
+	err := runTask(ctx, j)
+	duration := time.Since(start)
+
+	if err != nil {
+		log.Error().Err(err).Str("task", j.name).Msg("Scheduled task failed")
+	} else {
+		log.Debug().Str("task", j.name).Dur("duration", duration).Msg("Scheduled task completed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := start.Add(j.interval)
+	j.status.Runs++
+	j.status.LastRunAt = &start
+	j.status.LastDuration = duration
+	j.status.NextRunAt = &next
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = err.Error()
+	}
+}
+
+// runTask runs the task and turns a panic into an error so one failing
+// task does not stop the scheduler
+func runTask(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("task %s panicked: %v", j.name, p)
+		}
+	}()
+
+	return j.task(ctx)
+}
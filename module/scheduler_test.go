@@ -0,0 +1,72 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitScheduler_Register(t *testing.T) {
+	t.Run("Rejects invalid and duplicate tasks", func(t *testing.T) {
+		scheduler := NewScheduler()
+		task := func(_ context.Context) error { return nil }
+
+		require.NoError(t, scheduler.Register("task", time.Minute, task))
+		assert.Error(t, scheduler.Register("task", time.Minute, task))
+		assert.Error(t, scheduler.Register("other", 0, task))
+
+		jobs := scheduler.Jobs()
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "task", jobs[0].Name)
+		assert.Nil(t, jobs[0].LastRunAt)
+	})
+}
+
+func TestUnitScheduler_Run(t *testing.T) {
+	t.Run("Runs tasks and records their status", func(t *testing.T) {
+		scheduler := NewScheduler()
+
+		var runs atomic.Int64
+		require.NoError(t, scheduler.Register("ok", 10*time.Millisecond, func(_ context.Context) error {
+			runs.Add(1)
+			return nil
+		}))
+		require.NoError(t, scheduler.Register("failing", 10*time.Millisecond, func(_ context.Context) error {
+			return errors.New("boom")
+		}))
+		require.NoError(t, scheduler.Register("panicking", 10*time.Millisecond, func(_ context.Context) error {
+			panic("oops")
+		}))
+
+		scheduler.Start()
+		assert.Eventually(t, func() bool {
+			for _, job := range scheduler.Jobs() {
+				if job.Runs < 2 {
+					return false
+				}
+			}
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+		scheduler.Stop()
+
+		jobs := scheduler.Jobs()
+		require.Len(t, jobs, 3)
+		assert.Equal(t, "failing", jobs[0].Name)
+		assert.Equal(t, "boom", jobs[0].LastError)
+		assert.Equal(t, "ok", jobs[1].Name)
+		assert.Empty(t, jobs[1].LastError)
+		assert.NotNil(t, jobs[1].LastRunAt)
+		assert.Equal(t, runs.Load(), jobs[1].Runs)
+		assert.Equal(t, "panicking", jobs[2].Name)
+		assert.Contains(t, jobs[2].LastError, "panicked")
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"fmt"
+
+	"github.com/clivern/tut/db"
+)
+
+// SeedMetaKey marks users created by the seeder so they can be wiped
+// without touching real data
+const SeedMetaKey = "seeded"
+
+// SeededUser holds the credentials of a demo user
+type SeededUser struct {
+	User     *db.User
+	Password string
+	Created  bool
+}
+
+// Seeder creates deterministic demo data for development and testing
+type Seeder struct {
+	Repos *db.Repos
+}
+
+// NewSeeder creates a new seeder
+func NewSeeder(repos *db.Repos) *Seeder {
+	return &Seeder{Repos: repos}
+}
+
+// SeedUsers creates count demo users. The first user is an admin and the
+// rest alternate between the user and readonly roles. Users that already
+// exist are returned as they are.
+func (s *Seeder) SeedUsers(count int) ([]*SeededUser, error) {
+	userModule := NewUser(s.Repos.Users, s.Repos.UsersMeta)
+	seeded := make([]*SeededUser, 0, count)
+
+	for i := 1; i <= count; i++ {
+		email := fmt.Sprintf("demo%d@example.com", i)
+		password := fmt.Sprintf("Demo-Password-%d", i)
+
+		existing, err := s.Repos.Users.GetByEmail(email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			seeded = append(seeded, &SeededUser{User: existing, Password: password})
+			continue
+		}
+
+		user, err := userModule.CreateUser(&CreateUserOptions{
+			Email:    email,
+			Password: password,
+			Role:     seedRole(i),
+			IsActive: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.Repos.UsersMeta.Create(user.ID, SeedMetaKey, "1"); err != nil {
+			return nil, err
+		}
+
+		seeded = append(seeded, &SeededUser{User: user, Password: password, Created: true})
+	}
+
+	return seeded, nil
+}
+
+// Wipe deletes the seeded users with their metadata and sessions
+func (s *Seeder) Wipe() (int, error) {
+	userIDs, err := s.Repos.UsersMeta.ListUserIDsByKeyValue(SeedMetaKey, "1")
+	if err != nil {
+		return 0, err
+	}
+
+	userModule := NewUser(s.Repos.Users, s.Repos.UsersMeta)
+	for _, userID := range userIDs {
+		if err := s.Repos.Sessions.DeleteByUserID(userID); err != nil {
+			return 0, err
+		}
+		if err := userModule.DeleteUser(userID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(userIDs), nil
+}
+
+// seedRole returns the role of the i-th demo user
+func seedRole(i int) string {
+	switch {
+	case i == 1:
+		return db.UserRoleAdmin
+	case i%2 == 0:
+		return db.UserRoleUser
+	default:
+		return db.UserRoleReadonly
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUnitSeeder(t *testing.T) {
+	require.NoError(t, service.SetBcryptCost(bcrypt.MinCost))
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	_, err := testDB.Exec(`
+		CREATE TABLE sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			token VARCHAR(255) NOT NULL UNIQUE,
+			user_id INTEGER NOT NULL,
+			ip_address VARCHAR(45),
+			user_agent VARCHAR(500),
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	repos := db.NewRepos(testDB)
+	seeder := NewSeeder(repos)
+
+	realUser := &db.User{
+		Email:    "real@example.com",
+		Password: "password",
+		Role:     db.UserRoleAdmin,
+		APIKey:   "real",
+		IsActive: true,
+	}
+	require.NoError(t, repos.Users.Create(realUser))
+
+	t.Run("Seed users", func(t *testing.T) {
+		seeded, err := seeder.SeedUsers(3)
+		require.NoError(t, err)
+		require.Len(t, seeded, 3)
+
+		assert.Equal(t, "demo1@example.com", seeded[0].User.Email)
+		assert.Equal(t, db.UserRoleAdmin, seeded[0].User.Role)
+		assert.Equal(t, db.UserRoleUser, seeded[1].User.Role)
+		assert.Equal(t, db.UserRoleReadonly, seeded[2].User.Role)
+		assert.True(t, seeded[0].Created)
+		assert.True(t, service.ComparePassword(seeded[0].User.Password, seeded[0].Password))
+
+		meta, err := repos.UsersMeta.Get(seeded[0].User.ID, SeedMetaKey)
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+	})
+
+	t.Run("Seeding again keeps existing users", func(t *testing.T) {
+		seeded, err := seeder.SeedUsers(4)
+		require.NoError(t, err)
+		require.Len(t, seeded, 4)
+		assert.False(t, seeded[0].Created)
+		assert.True(t, seeded[3].Created)
+
+		count, err := repos.Users.Count()
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+
+	t.Run("Wipe removes only seeded users", func(t *testing.T) {
+		seeded, err := repos.Users.GetByEmail("demo1@example.com")
+		require.NoError(t, err)
+		require.NoError(t, repos.Sessions.Create(&db.Session{
+			Token:     "demo",
+			UserID:    seeded.ID,
+			ExpiresAt: time.Now().UTC().Add(time.Hour),
+		}))
+
+		wiped, err := seeder.Wipe()
+		require.NoError(t, err)
+		assert.Equal(t, 4, wiped)
+
+		count, err := repos.Users.Count()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		user, err := repos.Users.GetByEmail("real@example.com")
+		require.NoError(t, err)
+		assert.NotNil(t, user)
+
+		sessions, err := repos.Sessions.GetByUserID(seeded.ID)
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
@@ -95,20 +95,12 @@ func (s *SessionManager) RevokeUserSessions(userID int64) error {
 
 // GetUserSessions retrieves all active sessions for a user.
 func (s *SessionManager) GetUserSessions(userID int64) ([]*db.Session, error) {
-	sessions, err := s.SessionRepo.GetByUserID(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	var activeSessions []*db.Session
-	now := time.Now().UTC()
-	for _, session := range sessions {
-		if session.ExpiresAt.After(now) {
-			activeSessions = append(activeSessions, session)
-		}
-	}
+	return s.GetActiveSessions(userID)
+}
 
-	return activeSessions, nil
+// GetActiveSessions retrieves the non-expired sessions for a user, newest first.
+func (s *SessionManager) GetActiveSessions(userID int64) ([]*db.Session, error) {
+	return s.SessionRepo.GetActiveByUserID(userID)
 }
 
 // CleanupExpiredSessions removes all expired sessions from the database.
@@ -4,7 +4,11 @@
 
 package module
 
-import "github.com/clivern/tut/db"
+import (
+	"strings"
+
+	"github.com/clivern/tut/db"
+)
 
 // Settings handles the application settings
 type Settings struct {
@@ -34,17 +38,17 @@ func NewSettings(optionRepository *db.OptionRepository) *Settings {
 
 // UpdateSettings updates the application settings
 func (s *Settings) UpdateSettings(options *SettingsOptions) error {
-	err := s.OptionRepository.Update("app_url", options.ApplicationURL)
+	err := s.OptionRepository.Upsert("app_url", options.ApplicationURL)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("app_email", options.ApplicationEmail)
+	err = s.OptionRepository.Upsert("app_email", options.ApplicationEmail)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("app_name", options.ApplicationName)
+	err = s.OptionRepository.Upsert("app_name", options.ApplicationName)
 	if err != nil {
 		return err
 	}
@@ -53,32 +57,32 @@ func (s *Settings) UpdateSettings(options *SettingsOptions) error {
 	if options.MaintenanceMode {
 		maintenanceModeStr = "1"
 	}
-	err = s.OptionRepository.Update("maintenance_mode", maintenanceModeStr)
+	err = s.OptionRepository.Upsert("maintenance_mode", maintenanceModeStr)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("smtp_server", options.SMTPServer)
+	err = s.OptionRepository.Upsert("smtp_server", options.SMTPServer)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("smtp_port", options.SMTPPort)
+	err = s.OptionRepository.Upsert("smtp_port", options.SMTPPort)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("smtp_from_email", options.SMTPFromEmail)
+	err = s.OptionRepository.Upsert("smtp_from_email", options.SMTPFromEmail)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("smtp_username", options.SMTPUsername)
+	err = s.OptionRepository.Upsert("smtp_username", options.SMTPUsername)
 	if err != nil {
 		return err
 	}
 
-	err = s.OptionRepository.Update("smtp_password", options.SMTPPassword)
+	err = s.OptionRepository.Upsert("smtp_password", options.SMTPPassword)
 	if err != nil {
 		return err
 	}
@@ -87,7 +91,7 @@ func (s *Settings) UpdateSettings(options *SettingsOptions) error {
 	if options.SMTPUseTLS {
 		smtpUseTLSStr = "1"
 	}
-	err = s.OptionRepository.Update("smtp_use_tls", smtpUseTLSStr)
+	err = s.OptionRepository.Upsert("smtp_use_tls", smtpUseTLSStr)
 	if err != nil {
 		return err
 	}
@@ -109,65 +113,359 @@ func (s *Settings) GetSettings() (*SettingsOptions, error) {
 		SMTPPassword:     "",
 		SMTPUseTLS:       false,
 	}
-	option, err := s.OptionRepository.Get("app_url")
+
+	var err error
+	settings.ApplicationURL, err = s.OptionRepository.GetOrDefault("app_url", "")
+	if err != nil {
+		return nil, err
+	}
+
+	settings.ApplicationEmail, err = s.OptionRepository.GetOrDefault("app_email", "")
+	if err != nil {
+		return nil, err
+	}
+
+	settings.ApplicationName, err = s.OptionRepository.GetOrDefault("app_name", "")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := s.OptionRepository.GetOrDefault("maintenance_mode", "0")
+	if err != nil {
+		return nil, err
+	}
+	settings.MaintenanceMode = value == "1"
+
+	settings.SMTPServer, err = s.OptionRepository.GetOrDefault("smtp_server", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.ApplicationURL = option.Value
 
-	option, err = s.OptionRepository.Get("app_email")
+	settings.SMTPPort, err = s.OptionRepository.GetOrDefault("smtp_port", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.ApplicationEmail = option.Value
 
-	option, err = s.OptionRepository.Get("app_name")
+	settings.SMTPFromEmail, err = s.OptionRepository.GetOrDefault("smtp_from_email", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.ApplicationName = option.Value
 
-	option, err = s.OptionRepository.Get("maintenance_mode")
+	settings.SMTPUsername, err = s.OptionRepository.GetOrDefault("smtp_username", "")
+	if err != nil {
+		return nil, err
+	}
+
+	settings.SMTPPassword, err = s.OptionRepository.GetOrDefault("smtp_password", "")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = s.OptionRepository.GetOrDefault("smtp_use_tls", "0")
+	if err != nil {
+		return nil, err
+	}
+	settings.SMTPUseTLS = value == "1"
+
+	return settings, nil
+}
+
+// SecretValueChanged replaces the values of secret options in a settings diff
+const SecretValueChanged = "changed"
+
+// secretOptions are the options whose values are never exposed in listings or diffs
+var secretOptions = map[string]bool{
+	"smtp_password":      true,
+	"oidc_client_secret": true,
+}
+
+// IsSecretOption checks whether the value of an option must be kept out of listings and diffs
+func IsSecretOption(key string) bool {
+	return secretOptions[key]
+}
+
+// values returns the settings keyed by option name, in the order they are stored
+func (o *SettingsOptions) values() [][2]string {
+	return [][2]string{
+		{"app_url", o.ApplicationURL},
+		{"app_email", o.ApplicationEmail},
+		{"app_name", o.ApplicationName},
+		{"maintenance_mode", boolOption(o.MaintenanceMode)},
+		{"smtp_server", o.SMTPServer},
+		{"smtp_port", o.SMTPPort},
+		{"smtp_from_email", o.SMTPFromEmail},
+		{"smtp_username", o.SMTPUsername},
+		{"smtp_password", o.SMTPPassword},
+		{"smtp_use_tls", boolOption(o.SMTPUseTLS)},
+	}
+}
+
+// DiffSettings returns the options that differ between before and after. A
+// changed option maps to its from and to values, a changed secret option maps
+// to SecretValueChanged so its values never end up in the activity log.
+func DiffSettings(before, after *SettingsOptions) map[string]interface{} {
+	return diffOptions(before.values(), after.values())
+}
+
+// diffOptions compares two lists of option values in the same order
+func diffOptions(before, after [][2]string) map[string]interface{} {
+	changes := map[string]interface{}{}
+
+	for i, option := range after {
+		key, from, to := option[0], before[i][1], option[1]
+		if from == to {
+			continue
+		}
+
+		if IsSecretOption(key) {
+			changes[key] = SecretValueChanged
+			continue
+		}
+		changes[key] = map[string]string{"from": from, "to": to}
+	}
+
+	return changes
+}
+
+// boolOption returns the stored value of a boolean option
+func boolOption(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// OIDCSettings contains the single sign-on configuration
+type OIDCSettings struct {
+	Enabled           bool
+	IssuerURL         string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string
+	DefaultRole       string
+	LocalLoginEnabled bool
+}
+
+// GetOIDCSettings retrieves the single sign-on settings
+func (s *Settings) GetOIDCSettings() (*OIDCSettings, error) {
+	settings := &OIDCSettings{}
+
+	value, err := s.OptionRepository.GetOrDefault("oidc_enabled", "0")
 	if err != nil {
 		return nil, err
 	}
-	settings.MaintenanceMode = option.Value == "1"
+	settings.Enabled = value == "1"
 
-	option, err = s.OptionRepository.Get("smtp_server")
+	settings.IssuerURL, err = s.OptionRepository.GetOrDefault("oidc_issuer_url", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPServer = option.Value
 
-	option, err = s.OptionRepository.Get("smtp_port")
+	settings.ClientID, err = s.OptionRepository.GetOrDefault("oidc_client_id", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPPort = option.Value
 
-	option, err = s.OptionRepository.Get("smtp_from_email")
+	settings.ClientSecret, err = s.OptionRepository.GetOrDefault("oidc_client_secret", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPFromEmail = option.Value
 
-	option, err = s.OptionRepository.Get("smtp_username")
+	settings.RedirectURL, err = s.OptionRepository.GetOrDefault("oidc_redirect_url", "")
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPUsername = option.Value
 
-	option, err = s.OptionRepository.Get("smtp_password")
+	settings.DefaultRole, err = s.OptionRepository.GetOrDefault("oidc_default_role", db.UserRoleReadonly)
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPPassword = option.Value
 
-	option, err = s.OptionRepository.Get("smtp_use_tls")
+	settings.LocalLoginEnabled, err = s.IsLocalLoginEnabled()
 	if err != nil {
 		return nil, err
 	}
-	settings.SMTPUseTLS = option.Value == "1"
 
 	return settings, nil
 }
+
+// UpdateOIDCSettings updates the single sign-on settings
+func (s *Settings) UpdateOIDCSettings(options *OIDCSettings) error {
+	enabledStr := "0"
+	if options.Enabled {
+		enabledStr = "1"
+	}
+	err := s.OptionRepository.Upsert("oidc_enabled", enabledStr)
+	if err != nil {
+		return err
+	}
+
+	err = s.OptionRepository.Upsert("oidc_issuer_url", options.IssuerURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.OptionRepository.Upsert("oidc_client_id", options.ClientID)
+	if err != nil {
+		return err
+	}
+
+	err = s.OptionRepository.Upsert("oidc_client_secret", options.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	err = s.OptionRepository.Upsert("oidc_redirect_url", options.RedirectURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.OptionRepository.Upsert("oidc_default_role", options.DefaultRole)
+	if err != nil {
+		return err
+	}
+
+	localLoginStr := "0"
+	if options.LocalLoginEnabled {
+		localLoginStr = "1"
+	}
+	return s.OptionRepository.Upsert("local_login_enabled", localLoginStr)
+}
+
+// values returns the single sign-on settings keyed by option name
+func (o *OIDCSettings) values() [][2]string {
+	return [][2]string{
+		{"oidc_enabled", boolOption(o.Enabled)},
+		{"oidc_issuer_url", o.IssuerURL},
+		{"oidc_client_id", o.ClientID},
+		{"oidc_client_secret", o.ClientSecret},
+		{"oidc_redirect_url", o.RedirectURL},
+		{"oidc_default_role", o.DefaultRole},
+		{"local_login_enabled", boolOption(o.LocalLoginEnabled)},
+	}
+}
+
+// DiffOIDCSettings returns the single sign-on options that differ between
+// before and after, the client secret is masked like in DiffSettings.
+func DiffOIDCSettings(before, after *OIDCSettings) map[string]interface{} {
+	return diffOptions(before.values(), after.values())
+}
+
+// IsLocalLoginEnabled checks whether email and password login is allowed
+func (s *Settings) IsLocalLoginEnabled() (bool, error) {
+	value, err := s.OptionRepository.GetOrDefault("local_login_enabled", "1")
+	if err != nil {
+		return false, err
+	}
+	return value == "1", nil
+}
+
+// RegistrationSettings contains the self-registration configuration
+type RegistrationSettings struct {
+	Enabled bool
+	// BlockedDomains are email domain patterns rejected on registration, e.g. *.example.com
+	BlockedDomains []string
+}
+
+// GetRegistrationSettings retrieves the self-registration settings
+func (s *Settings) GetRegistrationSettings() (*RegistrationSettings, error) {
+	settings := &RegistrationSettings{BlockedDomains: []string{}}
+
+	value, err := s.OptionRepository.GetOrDefault("registration_enabled", "0")
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = value == "1"
+
+	value, err = s.OptionRepository.GetOrDefault("registration_blocked_domains", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range strings.Split(value, "\n") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			settings.BlockedDomains = append(settings.BlockedDomains, domain)
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateRegistrationSettings updates the self-registration settings
+func (s *Settings) UpdateRegistrationSettings(options *RegistrationSettings) error {
+	enabledStr := "0"
+	if options.Enabled {
+		enabledStr = "1"
+	}
+	err := s.OptionRepository.Upsert("registration_enabled", enabledStr)
+	if err != nil {
+		return err
+	}
+
+	domains := make([]string, 0, len(options.BlockedDomains))
+	for _, domain := range options.BlockedDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return s.OptionRepository.Upsert("registration_blocked_domains", strings.Join(domains, "\n"))
+}
+
+// values returns the self-registration settings keyed by option name
+func (o *RegistrationSettings) values() [][2]string {
+	return [][2]string{
+		{"registration_enabled", boolOption(o.Enabled)},
+		{"registration_blocked_domains", strings.Join(o.BlockedDomains, ", ")},
+	}
+}
+
+// DiffRegistrationSettings returns the self-registration options that differ
+// between before and after.
+func DiffRegistrationSettings(before, after *RegistrationSettings) map[string]interface{} {
+	return diffOptions(before.values(), after.values())
+}
+
+// CORSSettings contains the cross-origin configuration of the JSON API
+type CORSSettings struct {
+	AllowedOrigins []string
+}
+
+// GetCORSSettings retrieves the cross-origin settings
+func (s *Settings) GetCORSSettings() (*CORSSettings, error) {
+	settings := &CORSSettings{AllowedOrigins: []string{}}
+
+	value, err := s.OptionRepository.GetOrDefault("cors_allowed_origins", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, origin := range strings.Split(value, "\n") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			settings.AllowedOrigins = append(settings.AllowedOrigins, origin)
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateCORSSettings updates the cross-origin settings
+func (s *Settings) UpdateCORSSettings(options *CORSSettings) error {
+	origins := make([]string, 0, len(options.AllowedOrigins))
+	for _, origin := range options.AllowedOrigins {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return s.OptionRepository.Upsert("cors_allowed_origins", strings.Join(origins, "\n"))
+}
+
+// values returns the cross-origin settings keyed by option name
+func (o *CORSSettings) values() [][2]string {
+	return [][2]string{
+		{"cors_allowed_origins", strings.Join(o.AllowedOrigins, ", ")},
+	}
+}
+
+// DiffCORSSettings returns the cross-origin options that differ between
+// before and after.
+func DiffCORSSettings(before, after *CORSSettings) map[string]interface{} {
+	return diffOptions(before.values(), after.values())
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/clivern/tut/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSettingsTestDB(t *testing.T) *sql.DB {
+	testDB, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	testDB.SetMaxOpenConns(1)
+
+	_, err = testDB.Exec(`
+		CREATE TABLE options (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key VARCHAR(255) NOT NULL UNIQUE,
+			value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	require.NoError(t, err)
+
+	return testDB
+}
+
+func TestUnitSettings_GetSettings(t *testing.T) {
+	t.Run("Missing options return defaults", func(t *testing.T) {
+		testDB := setupSettingsTestDB(t)
+		defer testDB.Close()
+
+		settings := NewSettings(db.NewOptionRepository(testDB))
+
+		result, err := settings.GetSettings()
+		require.NoError(t, err)
+		assert.Equal(t, &SettingsOptions{}, result)
+	})
+
+	t.Run("Update creates missing options", func(t *testing.T) {
+		testDB := setupSettingsTestDB(t)
+		defer testDB.Close()
+
+		settings := NewSettings(db.NewOptionRepository(testDB))
+
+		options := &SettingsOptions{
+			ApplicationURL:   "https://tut.example.com",
+			ApplicationEmail: "admin@example.com",
+			ApplicationName:  "Tut",
+			MaintenanceMode:  true,
+			SMTPServer:       "smtp.example.com",
+			SMTPPort:         "587",
+			SMTPFromEmail:    "no-reply@example.com",
+			SMTPUsername:     "mailer",
+			SMTPPassword:     "secret",
+			SMTPUseTLS:       true,
+		}
+		require.NoError(t, settings.UpdateSettings(options))
+
+		result, err := settings.GetSettings()
+		require.NoError(t, err)
+		assert.Equal(t, options, result)
+	})
+}
+
+func TestUnitSettings_DiffSettings(t *testing.T) {
+	before := &SettingsOptions{
+		ApplicationName: "Tut",
+		SMTPServer:      "smtp.example.com",
+		SMTPPassword:    "old-secret",
+	}
+
+	t.Run("Unchanged settings have no changes", func(t *testing.T) {
+		after := *before
+		assert.Empty(t, DiffSettings(before, &after))
+	})
+
+	t.Run("Changed settings keep from and to values", func(t *testing.T) {
+		after := *before
+		after.SMTPServer = "mail.example.com"
+		after.MaintenanceMode = true
+
+		assert.Equal(t, map[string]interface{}{
+			"smtp_server":      map[string]string{"from": "smtp.example.com", "to": "mail.example.com"},
+			"maintenance_mode": map[string]string{"from": "0", "to": "1"},
+		}, DiffSettings(before, &after))
+	})
+
+	t.Run("Secret values are redacted", func(t *testing.T) {
+		after := *before
+		after.SMTPPassword = "new-secret"
+
+		assert.Equal(t, map[string]interface{}{
+			"smtp_password": SecretValueChanged,
+		}, DiffSettings(before, &after))
+	})
+}
+
+func TestUnitSettings_DiffSectionSettings(t *testing.T) {
+	t.Run("Single sign-on client secret is redacted", func(t *testing.T) {
+		before := &OIDCSettings{ClientID: "tut", ClientSecret: "old-secret", DefaultRole: "user"}
+		after := *before
+		after.ClientSecret = "new-secret"
+		after.DefaultRole = "readonly"
+
+		assert.Equal(t, map[string]interface{}{
+			"oidc_client_secret": SecretValueChanged,
+			"oidc_default_role":  map[string]string{"from": "user", "to": "readonly"},
+		}, DiffOIDCSettings(before, &after))
+	})
+
+	t.Run("Lists are compared as a whole", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{
+			"registration_blocked_domains": map[string]string{"from": "a.com", "to": "a.com, b.com"},
+		}, DiffRegistrationSettings(
+			&RegistrationSettings{BlockedDomains: []string{"a.com"}},
+			&RegistrationSettings{BlockedDomains: []string{"a.com", "b.com"}},
+		))
+
+		assert.Empty(t, DiffCORSSettings(
+			&CORSSettings{AllowedOrigins: []string{"https://a.com"}},
+			&CORSSettings{AllowedOrigins: []string{"https://a.com"}},
+		))
+	})
+}
@@ -13,10 +13,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrResetUnavailable is returned by Reset when the setup has no access to every repository
+var ErrResetUnavailable = errors.New("reset requires a setup created from repositories")
+
 // Setup handles the initial installation and configuration of the application.
 type Setup struct {
 	OptionRepository *db.OptionRepository
 	UserRepository   *db.UserRepository
+	// Repos gives Reset access to every table, it is only set by NewSetupFromRepos
+	Repos *db.Repos
 }
 
 // SetupOptions contains the configuration options for application setup.
@@ -33,18 +38,27 @@ func NewSetup(optionRepository *db.OptionRepository, userRepository *db.UserRepo
 	return &Setup{OptionRepository: optionRepository, UserRepository: userRepository}
 }
 
+// NewSetupFromRepos creates a new Setup instance that can also reset the application.
+func NewSetupFromRepos(repos *db.Repos) *Setup {
+	return &Setup{OptionRepository: repos.Options, UserRepository: repos.Users, Repos: repos}
+}
+
 // IsInstalled checks whether the application has been installed.
-func (s *Setup) IsInstalled() bool {
+func (s *Setup) IsInstalled() (bool, error) {
 	option, err := s.OptionRepository.Get("is_installed")
 	if err != nil {
-		return false
+		return false, err
 	}
-	return option != nil
+	return option != nil, nil
 }
 
 // Install performs the initial application installation with the provided options.
 func (s *Setup) Install(options *SetupOptions) error {
-	if s.IsInstalled() {
+	installed, err := s.IsInstalled()
+	if err != nil {
+		return err
+	}
+	if installed {
 		return errors.New("application is already installed")
 	}
 
@@ -128,3 +142,27 @@ func (s *Setup) Install(options *SetupOptions) error {
 
 	return nil
 }
+
+// Reset deletes all application data, children before their parents, so
+// setup can run again. Run it inside a transaction so a failure keeps the data.
+func (s *Setup) Reset() error {
+	if s.Repos == nil {
+		return ErrResetUnavailable
+	}
+
+	steps := []func() error{
+		s.Repos.Sessions.DeleteAll,
+		s.Repos.Activities.DeleteAll,
+		s.Repos.UsersMeta.DeleteAll,
+		s.Repos.Users.DeleteAll,
+		s.Repos.Options.DeleteAll,
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
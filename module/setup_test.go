@@ -0,0 +1,78 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/clivern/tut/migration"
+	"github.com/clivern/tut/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUnitSetup_Reset(t *testing.T) {
+	require.NoError(t, service.SetBcryptCost(bcrypt.MinCost))
+	defer service.SetBcryptCost(service.DefaultBcryptCost)
+
+	testDB, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tut.db"))
+	require.NoError(t, err)
+	defer testDB.Close()
+
+	mgr := migration.NewManager(testDB, "sqlite")
+	for _, m := range migration.GetAll() {
+		mgr.Register(m)
+	}
+	require.NoError(t, mgr.Up())
+
+	require.NoError(t, NewSetup(db.NewOptionRepository(testDB), db.NewUserRepository(testDB)).Install(&SetupOptions{
+		ApplicationURL:   "https://tut.example.com",
+		ApplicationEmail: "app@example.com",
+		ApplicationName:  "Tut",
+		AdminEmail:       "admin@example.com",
+		AdminPassword:    "Password123!",
+	}))
+
+	repos := db.NewRepos(testDB)
+	admin, err := repos.Users.GetByEmail("admin@example.com")
+	require.NoError(t, err)
+	require.NoError(t, repos.UsersMeta.Create(admin.ID, "theme", "dark"))
+	_, err = NewSessionManager(repos.Sessions, repos.Users).CreateSession(admin.ID, time.Hour, "127.0.0.1", "test")
+	require.NoError(t, err)
+	require.NoError(t, repos.Activities.Create(&db.Activity{UserID: &admin.ID, Action: "user.login", EntityType: "user"}))
+
+	t.Run("Reset requires the repositories", func(t *testing.T) {
+		err := NewSetup(repos.Options, repos.Users).Reset()
+		assert.ErrorIs(t, err, ErrResetUnavailable)
+	})
+
+	t.Run("Reset deletes all data", func(t *testing.T) {
+		err := db.WithTx(context.Background(), testDB, func(tx *db.Repos) error {
+			return NewSetupFromRepos(tx).Reset()
+		})
+		require.NoError(t, err)
+
+		for _, table := range []string{"sessions", "activities", "users_meta", "users", "options"} {
+			var count int
+			require.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+			assert.Zero(t, count, table)
+		}
+
+		installed, err := NewSetupFromRepos(repos).IsInstalled()
+		require.NoError(t, err)
+		assert.False(t, installed)
+
+		var applied int
+		require.NoError(t, testDB.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&applied))
+		assert.Equal(t, len(migration.GetAll()), applied)
+	})
+}
@@ -6,6 +6,8 @@ package module
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/clivern/tut/db"
@@ -17,16 +19,18 @@ import (
 var (
 	ErrUserNotFound           = errors.New("user not found")
 	ErrUserEmailAlreadyExists = errors.New("user with this email already exists")
+	ErrUserSSOManaged         = errors.New("user password is managed by single sign-on")
 )
 
 // User handles user management operations.
 type User struct {
-	UserRepository *db.UserRepository
+	UserRepository     *db.UserRepository
+	UserMetaRepository *db.UserMetaRepository
 }
 
 // NewUser creates a new user module instance.
-func NewUser(repo *db.UserRepository) *User {
-	return &User{UserRepository: repo}
+func NewUser(repo *db.UserRepository, metaRepo *db.UserMetaRepository) *User {
+	return &User{UserRepository: repo, UserMetaRepository: metaRepo}
 }
 
 // CreateUserOptions contains options for creating a user.
@@ -118,6 +122,14 @@ func (u *User) UpdateUser(options *UpdateUserOptions) (*db.User, error) {
 
 	// Update password only if provided
 	if options.Password != "" {
+		ssoManaged, err := u.IsSSOManaged(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ssoManaged {
+			return nil, ErrUserSSOManaged
+		}
+
 		hashedPassword, err := service.HashPassword(options.Password)
 		if err != nil {
 			return nil, err
@@ -162,6 +174,116 @@ func (u *User) ListUsers(options *ListUsersOptions) (*ListUsersResult, error) {
 	}, nil
 }
 
+// IsSSOManaged checks whether the user account is managed by single sign-on.
+func (u *User) IsSSOManaged(userID int64) (bool, error) {
+	meta, err := u.UserMetaRepository.Get(userID, UserMetaAuthProvider)
+	if err != nil {
+		return false, err
+	}
+	return meta != nil && meta.Value == AuthProviderOIDC, nil
+}
+
+// IsPendingVerification checks whether the user registered but has not verified the email yet.
+func (u *User) IsPendingVerification(userID int64) (bool, error) {
+	meta, err := u.UserMetaRepository.Get(userID, UserMetaVerificationToken)
+	if err != nil {
+		return false, err
+	}
+	return meta != nil, nil
+}
+
+// UserStatus holds the account states kept in the user metadata.
+type UserStatus struct {
+	SSOManaged          bool
+	PendingVerification bool
+}
+
+// GetUserStatuses loads the statuses of several users with a single query.
+func (u *User) GetUserStatuses(userIDs []int64) (map[int64]UserStatus, error) {
+	entries, err := u.UserMetaRepository.GetKeysForUsers(
+		userIDs,
+		[]string{UserMetaAuthProvider, UserMetaVerificationToken},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int64]UserStatus, len(userIDs))
+	for _, userID := range userIDs {
+		meta := entries[userID]
+		_, pending := meta[UserMetaVerificationToken]
+		statuses[userID] = UserStatus{
+			SSOManaged:          meta[UserMetaAuthProvider] == AuthProviderOIDC,
+			PendingVerification: pending,
+		}
+	}
+
+	return statuses, nil
+}
+
+// ActivateUser activates a user and discards any pending email verification.
+func (u *User) ActivateUser(userID int64) (*db.User, error) {
+	user, err := u.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.IsActive = true
+	if err := u.UserRepository.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := u.UserMetaRepository.Delete(userID, UserMetaVerificationToken); err != nil {
+		return nil, err
+	}
+	if err := u.UserMetaRepository.Delete(userID, UserMetaVerificationExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ListInactiveUsers retrieves active users that did not login within the olderThan duration.
+func (u *User) ListInactiveUsers(olderThan time.Duration, limit, offset int) ([]*db.User, error) {
+	return u.UserRepository.ListInactive(olderThan, limit, offset)
+}
+
+// DeactivateInactiveUsers deactivates all active users that did not login within
+// the olderThan duration, except excludeUserID. It returns the deactivated users.
+func (u *User) DeactivateInactiveUsers(olderThan time.Duration, excludeUserID int64) ([]*db.User, error) {
+	const batchSize = 100
+
+	var deactivated []*db.User
+	skipped := 0
+
+	for {
+		// Deactivated users drop out of the result so only skipped users shift the offset
+		users, err := u.UserRepository.ListInactive(olderThan, batchSize, skipped)
+		if err != nil {
+			return deactivated, err
+		}
+		if len(users) == 0 {
+			return deactivated, nil
+		}
+
+		for _, user := range users {
+			if user.ID == excludeUserID {
+				skipped++
+				continue
+			}
+
+			user.IsActive = false
+			if err := u.UserRepository.Update(user); err != nil {
+				return deactivated, err
+			}
+			deactivated = append(deactivated, user)
+		}
+	}
+}
+
 // DeleteUser deletes a user by ID.
 func (u *User) DeleteUser(userID int64) error {
 	// Check if user exists
@@ -173,6 +295,155 @@ func (u *User) DeleteUser(userID int64) error {
 		return ErrUserNotFound
 	}
 
+	// Delete user metadata, the foreign key cascade is not enforced on SQLite
+	if err := u.UserMetaRepository.DeleteByUserID(userID); err != nil {
+		return err
+	}
+
 	// Delete user
 	return u.UserRepository.Delete(userID)
 }
+
+// User preference metadata
+const (
+	// UserMetaPreferencePrefix namespaces preferences so users can not read or
+	// overwrite the metadata the application keeps about them
+	UserMetaPreferencePrefix = "preference_"
+)
+
+// GetPreferences retrieves the preferences a user set, without the prefix.
+func (u *User) GetPreferences(userID int64) (map[string]string, error) {
+	entries, err := u.UserMetaRepository.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]string)
+	for key, value := range entries {
+		if name, ok := strings.CutPrefix(key, UserMetaPreferencePrefix); ok {
+			preferences[name] = value
+		}
+	}
+
+	return preferences, nil
+}
+
+// SetPreferences inserts or updates the preferences of a user.
+func (u *User) SetPreferences(userID int64, preferences map[string]string) error {
+	entries := make(map[string]string, len(preferences))
+	for name, value := range preferences {
+		entries[UserMetaPreferencePrefix+name] = value
+	}
+	return u.UserMetaRepository.SetMultiple(userID, entries)
+}
+
+// UIPreferences are the preferences clients read and replace through the
+// preferences endpoint
+var UIPreferences = []string{"date_format", "language", "page_size", "theme", "timezone"}
+
+// IsUIPreference reports whether the name is one of the UI preferences
+func IsUIPreference(name string) bool {
+	return slices.Contains(UIPreferences, name)
+}
+
+// GetUIPreferences retrieves the UI preferences a user set.
+func (u *User) GetUIPreferences(userID int64) (map[string]string, error) {
+	preferences, err := u.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range preferences {
+		if !IsUIPreference(name) {
+			delete(preferences, name)
+		}
+	}
+
+	return preferences, nil
+}
+
+// ReplaceUIPreferences sets the UI preferences of a user and removes the ones
+// missing from preferences. Run it inside a transaction so a failure leaves
+// the preferences unchanged.
+func (u *User) ReplaceUIPreferences(userID int64, preferences map[string]string) error {
+	if err := u.SetPreferences(userID, preferences); err != nil {
+		return err
+	}
+
+	for _, name := range UIPreferences {
+		if _, ok := preferences[name]; ok {
+			continue
+		}
+		if err := u.UserMetaRepository.Delete(userID, UserMetaPreferencePrefix+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ErrUserMetaReserved is returned when a reserved metadata key is changed
+var ErrUserMetaReserved = errors.New("user metadata key is reserved")
+
+// reservedUserMetaKeys are written by the application itself, they are never
+// returned or changed through the metadata API
+var reservedUserMetaKeys = map[string]bool{
+	UserMetaAuthProvider:          true,
+	UserMetaOIDCSubject:           true,
+	UserMetaVerificationToken:     true,
+	UserMetaVerificationExpiresAt: true,
+	UserMetaFailedLoginAttempts:   true,
+	UserMetaFailedLoginAt:         true,
+	SeedMetaKey:                   true,
+}
+
+// IsReservedUserMetaKey reports whether the key is written by the application itself
+func IsReservedUserMetaKey(key string) bool {
+	return reservedUserMetaKeys[key]
+}
+
+// ListMeta retrieves the metadata of a user without the reserved keys.
+func (u *User) ListMeta(userID int64) (map[string]string, error) {
+	if _, err := u.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := u.UserMetaRepository.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range entries {
+		if IsReservedUserMetaKey(key) {
+			delete(entries, key)
+		}
+	}
+
+	return entries, nil
+}
+
+// SetMeta inserts or updates a metadata entry of a user.
+func (u *User) SetMeta(userID int64, key, value string) error {
+	if IsReservedUserMetaKey(key) {
+		return ErrUserMetaReserved
+	}
+
+	if _, err := u.GetUser(userID); err != nil {
+		return err
+	}
+
+	return u.UserMetaRepository.Upsert(userID, key, value)
+}
+
+// DeleteMeta removes a metadata entry of a user.
+func (u *User) DeleteMeta(userID int64, key string) error {
+	if IsReservedUserMetaKey(key) {
+		return ErrUserMetaReserved
+	}
+
+	if _, err := u.GetUser(userID); err != nil {
+		return err
+	}
+
+	return u.UserMetaRepository.Delete(userID, key)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package module
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clivern/tut/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitUser_DeactivateInactiveUsers(t *testing.T) {
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	userModule := NewUser(userRepo, db.NewUserMetaRepository(testDB))
+
+	// More dormant users than a single batch
+	for i := 0; i < 150; i++ {
+		require.NoError(t, userRepo.Create(&db.User{
+			Email:    fmt.Sprintf("dormant%d@example.com", i),
+			Password: "password",
+			Role:     db.UserRoleUser,
+			APIKey:   fmt.Sprintf("dormant-%d", i),
+			IsActive: true,
+		}))
+	}
+
+	admin := &db.User{
+		Email:       "admin@example.com",
+		Password:    "password",
+		Role:        db.UserRoleAdmin,
+		APIKey:      "admin",
+		IsActive:    true,
+		LastLoginAt: time.Time{},
+	}
+	require.NoError(t, userRepo.Create(admin))
+
+	recent := &db.User{
+		Email:       "recent@example.com",
+		Password:    "password",
+		Role:        db.UserRoleUser,
+		APIKey:      "recent",
+		IsActive:    true,
+		LastLoginAt: time.Now().UTC(),
+	}
+	require.NoError(t, userRepo.Create(recent))
+
+	deactivated, err := userModule.DeactivateInactiveUsers(90*24*time.Hour, admin.ID)
+	require.NoError(t, err)
+	assert.Len(t, deactivated, 150)
+
+	remaining, err := userModule.ListInactiveUsers(90*24*time.Hour, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, admin.ID, remaining[0].ID)
+
+	user, err := userRepo.GetByID(recent.ID)
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+}
+
+func TestUnitUser_DeleteUser(t *testing.T) {
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	metaRepo := db.NewUserMetaRepository(testDB)
+	userModule := NewUser(userRepo, metaRepo)
+
+	user := &db.User{
+		Email:    "user@example.com",
+		Password: "password",
+		Role:     db.UserRoleUser,
+		APIKey:   "user",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+	require.NoError(t, metaRepo.Create(user.ID, "oidc_subject", "subject"))
+
+	require.NoError(t, userModule.DeleteUser(user.ID))
+
+	deleted, err := userRepo.GetByID(user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	meta, err := metaRepo.ListByUser(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, meta)
+
+	assert.ErrorIs(t, userModule.DeleteUser(user.ID), ErrUserNotFound)
+}
+
+func TestUnitUser_Meta(t *testing.T) {
+	testDB := setupOIDCModuleTestDB(t)
+	defer testDB.Close()
+
+	userRepo := db.NewUserRepository(testDB)
+	metaRepo := db.NewUserMetaRepository(testDB)
+	userModule := NewUser(userRepo, metaRepo)
+
+	user := &db.User{
+		Email:    "meta@example.com",
+		Password: "password",
+		Role:     db.UserRoleUser,
+		APIKey:   "meta",
+		IsActive: true,
+	}
+	require.NoError(t, userRepo.Create(user))
+	require.NoError(t, metaRepo.Create(user.ID, UserMetaOIDCSubject, "subject"))
+	require.NoError(t, userModule.SetPreferences(user.ID, map[string]string{"theme": "dark"}))
+
+	require.NoError(t, userModule.SetMeta(user.ID, "quota_mb", "100"))
+
+	meta, err := userModule.ListMeta(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"quota_mb": "100", "preference_theme": "dark"}, meta)
+
+	assert.ErrorIs(t, userModule.SetMeta(user.ID, UserMetaOIDCSubject, "other"), ErrUserMetaReserved)
+	assert.ErrorIs(t, userModule.DeleteMeta(user.ID, UserMetaFailedLoginAt), ErrUserMetaReserved)
+	assert.ErrorIs(t, userModule.SetMeta(user.ID+1, "quota_mb", "100"), ErrUserNotFound)
+
+	require.NoError(t, userModule.DeleteMeta(user.ID, "quota_mb"))
+
+	preferences, err := userModule.GetPreferences(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"theme": "dark"}, preferences)
+}
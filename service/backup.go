@@ -0,0 +1,565 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backup archive entries
+const (
+	BackupManifestFile = "backup.json"
+	BackupSQLiteFile   = "database.db"
+	BackupPostgresFile = "database.sql"
+	backupExtension    = ".tar.gz"
+)
+
+var (
+	// ErrBackupNotFound is returned when a backup file does not exist or has an invalid name
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrInvalidBackup is returned when a backup archive is malformed or its checksum does not match
+	ErrInvalidBackup = errors.New("invalid backup")
+	// ErrRestoreTargetNotEmpty is returned when restoring over an existing database without force
+	ErrRestoreTargetNotEmpty = errors.New("restore target is not empty")
+)
+
+// BackupConfig holds the database connection details used to dump the database
+type BackupConfig struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Database string
+	// DataSource is the path of the SQLite database file
+	DataSource string
+	// AppVersion is recorded in the manifest of new backups
+	AppVersion string
+}
+
+// BackupManifest describes the content of a backup archive
+type BackupManifest struct {
+	Driver        string    `json:"driver"`
+	Database      string    `json:"database"`
+	Checksum      string    `json:"checksum"`
+	AppVersion    string    `json:"appVersion,omitempty"`
+	SchemaVersion string    `json:"schemaVersion,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// BackupInfo describes a backup file in the backup directory
+type BackupInfo struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// BackupManager creates and lists database backups
+type BackupManager struct {
+	DB        *sql.DB
+	Config    BackupConfig
+	BackupDir string
+}
+
+// NewBackupManager creates a new backup manager
+func NewBackupManager(db *sql.DB, config BackupConfig, backupDir string) *BackupManager {
+	return &BackupManager{
+		DB:        db,
+		Config:    config,
+		BackupDir: backupDir,
+	}
+}
+
+// CreateBackup dumps the database and writes it with a manifest into a tar.gz archive at outputPath
+func (b *BackupManager) CreateBackup(outputPath string) error {
+	workDir, err := os.MkdirTemp("", "tut-backup-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	dumpName := BackupSQLiteFile
+	if b.Config.Driver != "sqlite" {
+		dumpName = BackupPostgresFile
+	}
+	dumpPath := filepath.Join(workDir, dumpName)
+
+	if err := b.dumpDatabase(dumpPath); err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(dumpPath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(BackupManifest{
+		Driver:        b.Config.Driver,
+		Database:      dumpName,
+		Checksum:      checksum,
+		AppVersion:    b.Config.AppVersion,
+		SchemaVersion: b.schemaVersion(),
+		CreatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(workDir, BackupManifestFile)
+	if err := os.WriteFile(manifestPath, manifest, 0600); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Write next to the destination so the final rename is atomic
+	tmpPath := outputPath + ".tmp"
+	if err := writeArchive(tmpPath, workDir, []string{BackupManifestFile, dumpName}); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move backup to [%s]: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// CreateBackupInDir creates a backup in the backup directory. The name holds
+// the creation time and a random suffix and is reserved before the archive is
+// written, so concurrent backups never overwrite each other.
+func (b *BackupManager) CreateBackupInDir() (*BackupInfo, error) {
+	if err := os.MkdirAll(b.BackupDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf(
+		"tut-backup-%s-%s%s",
+		time.Now().UTC().Format("20060102T150405Z"),
+		hex.EncodeToString(suffix),
+		backupExtension,
+	)
+	path := filepath.Join(b.BackupDir, name)
+
+	// The empty file is replaced by CreateBackup once the archive is complete
+	reserved, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve backup name [%s]: %w", name, err)
+	}
+	reserved.Close()
+
+	if err := b.CreateBackup(path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackupInfo{
+		Name:      name,
+		Size:      stat.Size(),
+		CreatedAt: stat.ModTime().UTC(),
+	}, nil
+}
+
+// ListBackups lists the backups in the backup directory, newest first
+func (b *BackupManager) ListBackups() ([]BackupInfo, error) {
+	entries, err := os.ReadDir(b.BackupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), backupExtension) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		// An empty file is a backup that is still being written
+		if info.Size() == 0 {
+			continue
+		}
+
+		backups = append(backups, BackupInfo{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().UTC(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// GetBackupPath returns the path of a backup in the backup directory
+func (b *BackupManager) GetBackupPath(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || !strings.HasSuffix(name, backupExtension) {
+		return "", ErrBackupNotFound
+	}
+
+	path := filepath.Join(b.BackupDir, name)
+	stat, err := os.Stat(path)
+	if err != nil || stat.IsDir() || stat.Size() == 0 {
+		return "", ErrBackupNotFound
+	}
+
+	return path, nil
+}
+
+// RestoreBackup validates a backup archive and restores its database.
+// It refuses to overwrite a database that has data unless force is set.
+// The server must be stopped while restoring.
+func (b *BackupManager) RestoreBackup(inputPath string, force bool) (*BackupManifest, error) {
+	workDir, err := os.MkdirTemp("", "tut-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	if err := extractArchive(inputPath, workDir); err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if normalizeDriver(manifest.Driver) != normalizeDriver(b.Config.Driver) {
+		return nil, fmt.Errorf("%w: backup of a %s database cannot be restored into %s", ErrInvalidBackup, manifest.Driver, b.Config.Driver)
+	}
+
+	dumpPath := filepath.Join(workDir, manifest.Database)
+	checksum, err := fileChecksum(dumpPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is missing from the archive", ErrInvalidBackup, manifest.Database)
+	}
+	if checksum != manifest.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidBackup, manifest.Database)
+	}
+
+	if err := b.restoreDatabase(dumpPath, force); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// restoreDatabase replaces the database with the dump at path
+func (b *BackupManager) restoreDatabase(path string, force bool) error {
+	switch normalizeDriver(b.Config.Driver) {
+	case "sqlite":
+		target := b.Config.DataSource
+		if target == "" {
+			return errors.New("sqlite datasource is not configured")
+		}
+
+		if stat, err := os.Stat(target); err == nil && stat.Size() > 0 && !force {
+			return fmt.Errorf("%w: %s already exists, use force to overwrite it", ErrRestoreTargetNotEmpty, target)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
+
+		// Copy next to the target so the final rename is atomic
+		tmpPath := target + ".tmp"
+		if err := copyFile(path, tmpPath); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+		if err := os.Rename(tmpPath, target); err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to restore database to [%s]: %w", target, err)
+		}
+
+		// Stale WAL files belong to the replaced database
+		os.Remove(target + "-wal")
+		os.Remove(target + "-shm")
+		return nil
+	case "postgres":
+		var tables int
+		err := b.DB.QueryRow(
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public'",
+		).Scan(&tables)
+		if err != nil {
+			return fmt.Errorf("failed to inspect postgres database: %w", err)
+		}
+		if tables > 0 && !force {
+			return fmt.Errorf("%w: database %s has %d tables, use force to overwrite it", ErrRestoreTargetNotEmpty, b.Config.Database, tables)
+		}
+
+		cmd := exec.Command(
+			"psql",
+			"--host", b.Config.Host,
+			"--port", strconv.Itoa(b.Config.Port),
+			"--username", b.Config.Username,
+			"--dbname", b.Config.Database,
+			"--no-password",
+			"--set", "ON_ERROR_STOP=1",
+			"--single-transaction",
+			"--file", path,
+		)
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+b.Config.Password)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to restore postgres database: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported database driver: %s", b.Config.Driver)
+	}
+}
+
+// schemaVersion returns the latest applied migration or an empty string
+func (b *BackupManager) schemaVersion() string {
+	var version string
+	err := b.DB.QueryRow("SELECT version FROM migrations ORDER BY version DESC LIMIT 1").Scan(&version)
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
+// dumpDatabase writes a consistent copy of the database to path
+func (b *BackupManager) dumpDatabase(path string) error {
+	switch normalizeDriver(b.Config.Driver) {
+	case "sqlite":
+		// VACUUM INTO takes a consistent snapshot of a live database
+		if _, err := b.DB.Exec("VACUUM INTO ?", path); err != nil {
+			return fmt.Errorf("failed to dump sqlite database: %w", err)
+		}
+		return nil
+	case "postgres":
+		cmd := exec.Command(
+			"pg_dump",
+			"--host", b.Config.Host,
+			"--port", strconv.Itoa(b.Config.Port),
+			"--username", b.Config.Username,
+			"--dbname", b.Config.Database,
+			"--no-password",
+			"--clean",
+			"--if-exists",
+			"--file", path,
+		)
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+b.Config.Password)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to dump postgres database: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported database driver: %s", b.Config.Driver)
+	}
+}
+
+// writeArchive writes the named files of dir into a tar.gz archive
+func writeArchive(archivePath, dir string, names []string) error {
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup archive: %w", err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, name := range names {
+		if err := addArchiveFile(tarWriter, filepath.Join(dir, name), name); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// addArchiveFile adds a single file to the tar archive
+func addArchiveFile(tarWriter *tar.Writer, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	return nil
+}
+
+// extractArchive extracts the known backup entries of a tar.gz archive into dir
+func extractArchive(archivePath, dir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBackup, err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidBackup, err)
+		}
+
+		// Only the entries written by CreateBackup are extracted
+		switch header.Name {
+		case BackupManifestFile, BackupSQLiteFile, BackupPostgresFile:
+		default:
+			continue
+		}
+
+		out, err := os.OpenFile(filepath.Join(dir, header.Name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tarReader); err != nil {
+			out.Close()
+			return fmt.Errorf("%w: %s", ErrInvalidBackup, err)
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+}
+
+// readManifest reads and validates the manifest extracted into dir
+func readManifest(dir string) (*BackupManifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is missing from the archive", ErrInvalidBackup, BackupManifestFile)
+	}
+
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBackup, err)
+	}
+
+	if manifest.Database != BackupSQLiteFile && manifest.Database != BackupPostgresFile {
+		return nil, fmt.Errorf("%w: unknown database entry %q", ErrInvalidBackup, manifest.Database)
+	}
+	if manifest.Checksum == "" {
+		return nil, fmt.Errorf("%w: manifest has no checksum", ErrInvalidBackup)
+	}
+
+	return manifest, nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of a file
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyFile copies the file at src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// normalizeDriver maps driver aliases to a single name
+func normalizeDriver(driver string) string {
+	if driver == "postgresql" {
+		return "postgres"
+	}
+	return driver
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readArchive returns the content of each file in a tar.gz archive
+func readArchive(t *testing.T, path string) map[string][]byte {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = content
+	}
+
+	return files
+}
+
+func TestUnitBackupManager(t *testing.T) {
+	dir := t.TempDir()
+
+	testDB, err := sql.Open("sqlite3", filepath.Join(dir, "tut.db"))
+	require.NoError(t, err)
+	defer testDB.Close()
+
+	_, err = testDB.Exec("CREATE TABLE options (key TEXT, value TEXT)")
+	require.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO options (key, value) VALUES ('app_name', 'Tut')")
+	require.NoError(t, err)
+
+	backupDir := filepath.Join(dir, "backups")
+	manager := NewBackupManager(testDB, BackupConfig{Driver: "sqlite"}, backupDir)
+
+	t.Run("List without backup directory", func(t *testing.T) {
+		backups, err := manager.ListBackups()
+		require.NoError(t, err)
+		assert.Empty(t, backups)
+	})
+
+	t.Run("Create backup", func(t *testing.T) {
+		info, err := manager.CreateBackupInDir()
+		require.NoError(t, err)
+		assert.Greater(t, info.Size, int64(0))
+
+		path, err := manager.GetBackupPath(info.Name)
+		require.NoError(t, err)
+
+		files := readArchive(t, path)
+		require.Contains(t, files, BackupManifestFile)
+		require.Contains(t, files, BackupSQLiteFile)
+
+		var manifest BackupManifest
+		require.NoError(t, json.Unmarshal(files[BackupManifestFile], &manifest))
+		assert.Equal(t, "sqlite", manifest.Driver)
+		assert.Equal(t, BackupSQLiteFile, manifest.Database)
+
+		// The dump is a usable database
+		dumpPath := filepath.Join(t.TempDir(), "restored.db")
+		require.NoError(t, os.WriteFile(dumpPath, files[BackupSQLiteFile], 0600))
+		restored, err := sql.Open("sqlite3", dumpPath)
+		require.NoError(t, err)
+		defer restored.Close()
+
+		var value string
+		require.NoError(t, restored.QueryRow("SELECT value FROM options WHERE key = 'app_name'").Scan(&value))
+		assert.Equal(t, "Tut", value)
+
+		backups, err := manager.ListBackups()
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		assert.Equal(t, info.Name, backups[0].Name)
+	})
+
+	t.Run("Backups in the same second get distinct names", func(t *testing.T) {
+		first, err := manager.CreateBackupInDir()
+		require.NoError(t, err)
+		second, err := manager.CreateBackupInDir()
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Name, second.Name)
+
+		backups, err := manager.ListBackups()
+		require.NoError(t, err)
+		assert.Len(t, backups, 3)
+	})
+
+	t.Run("Backups still being written are hidden", func(t *testing.T) {
+		name := "tut-backup-20250101T000000Z-00000000.tar.gz"
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, name), nil, 0600))
+
+		backups, err := manager.ListBackups()
+		require.NoError(t, err)
+		assert.Len(t, backups, 3)
+
+		_, err = manager.GetBackupPath(name)
+		assert.ErrorIs(t, err, ErrBackupNotFound)
+	})
+
+	t.Run("Invalid backup names", func(t *testing.T) {
+		for _, name := range []string{"", "../tut.db", "tut.db", "missing.tar.gz", "../backups/x.tar.gz"} {
+			_, err := manager.GetBackupPath(name)
+			assert.ErrorIs(t, err, ErrBackupNotFound, name)
+		}
+	})
+
+	t.Run("Unsupported driver", func(t *testing.T) {
+		manager := NewBackupManager(testDB, BackupConfig{Driver: "mysql"}, backupDir)
+		assert.Error(t, manager.CreateBackup(filepath.Join(backupDir, "x.tar.gz")))
+	})
+}
+
+func TestUnitBackupManagerRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	testDB, err := sql.Open("sqlite3", filepath.Join(dir, "tut.db"))
+	require.NoError(t, err)
+	defer testDB.Close()
+
+	_, err = testDB.Exec("CREATE TABLE options (key TEXT, value TEXT)")
+	require.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO options (key, value) VALUES ('app_name', 'Tut')")
+	require.NoError(t, err)
+	_, err = testDB.Exec("CREATE TABLE migrations (version TEXT)")
+	require.NoError(t, err)
+	_, err = testDB.Exec("INSERT INTO migrations (version) VALUES ('20250101000001'), ('20250101000002')")
+	require.NoError(t, err)
+
+	archivePath := filepath.Join(dir, "backup.tar.gz")
+	manager := NewBackupManager(testDB, BackupConfig{Driver: "sqlite", AppVersion: "1.2.3"}, dir)
+	require.NoError(t, manager.CreateBackup(archivePath))
+
+	target := filepath.Join(dir, "restore", "tut.db")
+	restorer := NewBackupManager(nil, BackupConfig{Driver: "sqlite", DataSource: target}, dir)
+
+	t.Run("Manifest records versions and checksum", func(t *testing.T) {
+		var manifest BackupManifest
+		require.NoError(t, json.Unmarshal(readArchive(t, archivePath)[BackupManifestFile], &manifest))
+		assert.Equal(t, "1.2.3", manifest.AppVersion)
+		assert.Equal(t, "20250101000002", manifest.SchemaVersion)
+		assert.Len(t, manifest.Checksum, 64)
+	})
+
+	t.Run("Restore into an empty target", func(t *testing.T) {
+		manifest, err := restorer.RestoreBackup(archivePath, false)
+		require.NoError(t, err)
+		assert.Equal(t, "sqlite", manifest.Driver)
+
+		restored, err := sql.Open("sqlite3", target)
+		require.NoError(t, err)
+		defer restored.Close()
+
+		var value string
+		require.NoError(t, restored.QueryRow("SELECT value FROM options WHERE key = 'app_name'").Scan(&value))
+		assert.Equal(t, "Tut", value)
+	})
+
+	t.Run("Refuse to overwrite without force", func(t *testing.T) {
+		_, err := restorer.RestoreBackup(archivePath, false)
+		assert.ErrorIs(t, err, ErrRestoreTargetNotEmpty)
+
+		_, err = restorer.RestoreBackup(archivePath, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reject checksum mismatch", func(t *testing.T) {
+		workDir := t.TempDir()
+		files := readArchive(t, archivePath)
+
+		var manifest BackupManifest
+		require.NoError(t, json.Unmarshal(files[BackupManifestFile], &manifest))
+		manifest.Checksum = "0000"
+		content, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, BackupManifestFile), content, 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, BackupSQLiteFile), files[BackupSQLiteFile], 0600))
+
+		tampered := filepath.Join(workDir, "tampered.tar.gz")
+		require.NoError(t, writeArchive(tampered, workDir, []string{BackupManifestFile, BackupSQLiteFile}))
+
+		_, err = restorer.RestoreBackup(tampered, true)
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+	})
+
+	t.Run("Reject driver mismatch", func(t *testing.T) {
+		postgres := NewBackupManager(nil, BackupConfig{Driver: "postgresql"}, dir)
+		_, err := postgres.RestoreBackup(archivePath, true)
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+	})
+
+	t.Run("Reject files that are not backups", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "not-a-backup.tar.gz")
+		require.NoError(t, os.WriteFile(path, []byte("plain text"), 0600))
+
+		_, err := restorer.RestoreBackup(path, true)
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+	})
+}
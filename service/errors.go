@@ -0,0 +1,73 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net/http"
+)
+
+// Error codes returned in API error responses
+const (
+	ErrorCodeBadRequest       = "bad_request"
+	ErrorCodeValidationFailed = "validation_failed"
+	ErrorCodeUnauthorized     = "unauthorized"
+	ErrorCodeForbidden        = "forbidden"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeConflict         = "conflict"
+	ErrorCodeTooManyRequests  = "too_many_requests"
+	ErrorCodeInternal         = "internal_error"
+	ErrorCodeRequestTimeout   = "request_timeout"
+	ErrorCodeRequestTooLarge  = "request_too_large"
+
+	ErrorCodeInvalidCredentials   = "invalid_credentials"
+	ErrorCodeAccountInactive      = "account_inactive"
+	ErrorCodeAccountLocked        = "account_locked"
+	ErrorCodeAlreadyInstalled     = "already_installed"
+	ErrorCodeSetupRequired        = "setup_required"
+	ErrorCodeRegistrationDisabled = "registration_disabled"
+	ErrorCodeSSODisabled          = "sso_disabled"
+)
+
+// APIError represents an API error response body
+type APIError struct {
+	Code      string                 `json:"errorCode"`
+	Message   string                 `json:"errorMessage"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewAPIError creates a new API error
+func NewAPIError(code, message string) *APIError {
+	return &APIError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// WriteError writes an API error response with the given status code
+// The request ID is taken from the X-Request-ID response header when set
+func WriteError(w http.ResponseWriter, statusCode int, code, message string) error {
+	return WriteErrorWithDetails(w, statusCode, code, message, nil)
+}
+
+// WriteErrorWithDetails writes an API error response with extra details
+// that help clients handle the error programmatically
+func WriteErrorWithDetails(w http.ResponseWriter, statusCode int, code, message string, details map[string]interface{}) error {
+	apiError := NewAPIError(code, message)
+	apiError.RequestID = w.Header().Get("X-Request-ID")
+	apiError.Details = details
+
+	return WriteJSON(w, statusCode, apiError)
+}
+
+// WriteInternalError writes a 500 API error response
+func WriteInternalError(w http.ResponseWriter, message string) error {
+	return WriteError(w, http.StatusInternalServerError, ErrorCodeInternal, message)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitWriteError(t *testing.T) {
+	t.Run("Error body with request ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("X-Request-ID", "req-123")
+
+		err := WriteError(rec, http.StatusNotFound, ErrorCodeNotFound, "User not found")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{
+			"errorCode":    "not_found",
+			"errorMessage": "User not found",
+			"requestId":    "req-123",
+		}, body)
+	})
+
+	t.Run("Internal error without request ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		err := WriteInternalError(rec, "Failed to get user")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "internal_error", body["errorCode"])
+		assert.NotContains(t, body, "requestId")
+	})
+
+	t.Run("Error body with details", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		err := WriteErrorWithDetails(rec, http.StatusTooManyRequests, ErrorCodeTooManyRequests, "Too many requests", map[string]interface{}{
+			"retryAfter": 30,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "too_many_requests", body["errorCode"])
+		assert.Equal(t, map[string]interface{}{"retryAfter": float64(30)}, body["details"])
+	})
+
+	t.Run("Details are omitted when empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		err := WriteError(rec, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request")
+		require.NoError(t, err)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.NotContains(t, body, "details")
+	})
+}
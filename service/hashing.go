@@ -5,13 +5,52 @@
 package service
 
 import (
+	"fmt"
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultBcryptCost is the bcrypt cost factor used unless configured otherwise
+const DefaultBcryptCost = 12
+
+var (
+	bcryptCost   = DefaultBcryptCost
+	bcryptCostMu sync.RWMutex
+)
+
+// SetBcryptCost sets the bcrypt cost factor used by HashPassword.
+// A cost of zero keeps the current value.
+func SetBcryptCost(cost int) error {
+	if cost == 0 {
+		return nil
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+
+	bcryptCostMu.Lock()
+	defer bcryptCostMu.Unlock()
+	bcryptCost = cost
+	return nil
+}
+
+// GetBcryptCost returns the bcrypt cost factor used by HashPassword
+func GetBcryptCost() int {
+	bcryptCostMu.RLock()
+	defer bcryptCostMu.RUnlock()
+	return bcryptCost
+}
+
 // HashPassword generates a bcrypt hash from a plain text password.
-// It uses the default cost factor (bcrypt.DefaultCost = 10).
+// It uses the configured cost factor (DefaultBcryptCost = 12).
 func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return HashPasswordWithCost(password, GetBcryptCost())
+}
+
+// HashPasswordWithCost generates a bcrypt hash using the given cost factor.
+func HashPasswordWithCost(password string, cost int) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
 	if err != nil {
 		return "", err
 	}
@@ -19,6 +58,7 @@ func HashPassword(password string) (string, error) {
 }
 
 // ComparePassword compares a bcrypt hashed password with a plain text password.
+// bcrypt compares the derived hashes in constant time.
 func ComparePassword(hashedPassword, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUnitHashPassword(t *testing.T) {
@@ -129,3 +130,53 @@ func TestUnitHashAndComparePasswordIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestUnitSetBcryptCost(t *testing.T) {
+	defer SetBcryptCost(DefaultBcryptCost)
+
+	t.Run("Default cost", func(t *testing.T) {
+		assert.Equal(t, DefaultBcryptCost, GetBcryptCost())
+	})
+
+	t.Run("Configured cost is used for new hashes", func(t *testing.T) {
+		assert.NoError(t, SetBcryptCost(bcrypt.MinCost))
+
+		hashed, err := HashPassword("mySecurePassword123")
+		assert.NoError(t, err)
+
+		cost, err := bcrypt.Cost([]byte(hashed))
+		assert.NoError(t, err)
+		assert.Equal(t, bcrypt.MinCost, cost)
+	})
+
+	t.Run("Zero keeps the current cost", func(t *testing.T) {
+		assert.NoError(t, SetBcryptCost(0))
+		assert.Equal(t, bcrypt.MinCost, GetBcryptCost())
+	})
+
+	t.Run("Out of range cost is rejected", func(t *testing.T) {
+		assert.Error(t, SetBcryptCost(bcrypt.MaxCost+1))
+		assert.Error(t, SetBcryptCost(1))
+		assert.Equal(t, bcrypt.MinCost, GetBcryptCost())
+	})
+}
+
+func benchmarkHashPassword(b *testing.B, cost int) {
+	for i := 0; i < b.N; i++ {
+		if _, err := HashPasswordWithCost("mySecurePassword123", cost); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashPasswordCost10(b *testing.B) {
+	benchmarkHashPassword(b, 10)
+}
+
+func BenchmarkHashPasswordCost12(b *testing.B) {
+	benchmarkHashPassword(b, 12)
+}
+
+func BenchmarkHashPasswordCost14(b *testing.B) {
+	benchmarkHashPassword(b, 14)
+}
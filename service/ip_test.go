@@ -0,0 +1,142 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		realIP         string
+		expectedValue  string
+	}{
+		{
+			name:          "Remote address without proxy",
+			remoteAddr:    "203.0.113.10:52341",
+			expectedValue: "203.0.113.10",
+		},
+		{
+			name:          "Headers are ignored when no proxy is trusted",
+			remoteAddr:    "10.0.0.2:52341",
+			forwardedFor:  "203.0.113.10",
+			realIP:        "203.0.113.11",
+			expectedValue: "10.0.0.2",
+		},
+		{
+			name:           "Spoofed headers from an untrusted peer are ignored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "198.51.100.20:52341",
+			forwardedFor:   "203.0.113.10",
+			realIP:         "203.0.113.11",
+			expectedValue:  "198.51.100.20",
+		},
+		{
+			name:           "Single trusted proxy",
+			trustedProxies: []string{"10.0.0.2"},
+			remoteAddr:     "10.0.0.2:52341",
+			forwardedFor:   "203.0.113.10",
+			expectedValue:  "203.0.113.10",
+		},
+		{
+			name:           "Multiple hops stop at the first untrusted hop",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52341",
+			forwardedFor:   "198.51.100.1, 203.0.113.10, 10.0.0.5",
+			expectedValue:  "203.0.113.10",
+		},
+		{
+			name:           "Spoofed leftmost hop is not trusted",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52341",
+			forwardedFor:   "1.2.3.4, 203.0.113.10",
+			expectedValue:  "203.0.113.10",
+		},
+		{
+			name:           "All hops trusted returns the leftmost",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52341",
+			forwardedFor:   "10.0.0.9, 10.0.0.5",
+			expectedValue:  "10.0.0.9",
+		},
+		{
+			name:           "IPv6 multiple hops",
+			trustedProxies: []string{"::1/128", "fd00::/8"},
+			remoteAddr:     "[::1]:52341",
+			forwardedFor:   "2001:db8::1, fd00::2",
+			expectedValue:  "2001:db8::1",
+		},
+		{
+			name:           "IPv6 peer not trusted",
+			trustedProxies: []string{"fd00::/8"},
+			remoteAddr:     "[2001:db8::5]:52341",
+			forwardedFor:   "2001:db8::1",
+			expectedValue:  "2001:db8::5",
+		},
+		{
+			name:           "Real IP from a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52341",
+			realIP:         "198.51.100.7",
+			expectedValue:  "198.51.100.7",
+		},
+		{
+			name:           "Malformed hop stops the walk",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52341",
+			forwardedFor:   "203.0.113.10, unknown, 10.0.0.5",
+			expectedValue:  "10.0.0.5",
+		},
+		{
+			name:           "Invalid headers fall back to remote address",
+			trustedProxies: []string{"2001:db8::/32"},
+			remoteAddr:     "[2001:db8::2]:443",
+			forwardedFor:   "unknown",
+			realIP:         "invalid",
+			expectedValue:  "2001:db8::2",
+		},
+		{
+			name:          "Remote address without port",
+			remoteAddr:    "203.0.113.10",
+			expectedValue: "203.0.113.10",
+		},
+	}
+
+	defer SetTrustedProxies(nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, SetTrustedProxies(tt.trustedProxies))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			assert.Equal(t, tt.expectedValue, ClientIP(req))
+		})
+	}
+}
+
+func TestUnitSetTrustedProxies(t *testing.T) {
+	defer SetTrustedProxies(nil)
+
+	assert.NoError(t, SetTrustedProxies(PrivateNetworks))
+	assert.NoError(t, SetTrustedProxies([]string{"10.0.0.1", "::1"}))
+	assert.Error(t, SetTrustedProxies([]string{"10.0.0.0/33"}))
+	assert.Error(t, SetTrustedProxies([]string{"proxy.local"}))
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP timeouts so an unreachable server can not hang the request sending the mail
+var (
+	smtpDialTimeout    = 10 * time.Second
+	smtpSessionTimeout = 30 * time.Second
+)
+
+// SMTPConfig holds the SMTP server configuration
+type SMTPConfig struct {
+	Server    string
+	Port      string
+	FromEmail string
+	Username  string
+	Password  string
+	// UseTLS connects with implicit TLS, otherwise STARTTLS is used when offered
+	UseTLS bool
+}
+
+// SendMail sends a plain text email through the SMTP server
+func SendMail(config *SMTPConfig, to, subject, body string) error {
+	if config.Server == "" || config.FromEmail == "" {
+		return errors.New("smtp server is not configured")
+	}
+
+	addr := net.JoinHostPort(config.Server, config.Port)
+	tlsConfig := &tls.Config{ServerName: config.Server}
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	var err error
+
+	if config.UseTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server [%s]: %w", addr, err)
+	}
+
+	// The deadline also covers a server that accepts the connection but never answers
+	if err := conn.SetDeadline(time.Now().Add(smtpSessionTimeout)); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to set smtp deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, config.Server)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+
+	if !config.UseTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return fmt.Errorf("failed to start tls: %w", err)
+			}
+		}
+	}
+	defer client.Close()
+
+	if config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			auth := smtp.PlainAuth("", config.Username, config.Password, config.Server)
+			if err := client.Auth(auth); err != nil {
+				return fmt.Errorf("failed to authenticate with smtp server: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(config.FromEmail); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := writer.Write(buildMessage(config.FromEmail, to, subject, body)); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage builds the raw email, header values are stripped of line breaks
+func buildMessage(from, to, subject, body string) []byte {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", sanitizeHeader(from))
+	fmt.Fprintf(&msg, "To: %s\r\n", sanitizeHeader(to))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", sanitizeHeader(subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return msg.Bytes()
+}
+
+// sanitizeHeader removes characters that would allow header injection
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
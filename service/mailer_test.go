@@ -0,0 +1,64 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitBuildMessage(t *testing.T) {
+	t.Run("Message contains headers and body", func(t *testing.T) {
+		msg := string(buildMessage("noreply@example.com", "user@example.com", "Verify", "Hello\nWorld"))
+
+		assert.Contains(t, msg, "From: noreply@example.com\r\n")
+		assert.Contains(t, msg, "To: user@example.com\r\n")
+		assert.Contains(t, msg, "Subject: Verify\r\n")
+		assert.True(t, strings.HasSuffix(msg, "\r\n\r\nHello\r\nWorld"))
+	})
+
+	t.Run("Header injection is stripped", func(t *testing.T) {
+		msg := string(buildMessage("noreply@example.com", "user@example.com\r\nBcc: evil@example.com", "Hi", ""))
+
+		assert.NotContains(t, msg, "\r\nBcc:")
+		assert.Contains(t, msg, "To: user@example.comBcc: evil@example.com\r\n")
+	})
+}
+
+func TestUnitSendMailNotConfigured(t *testing.T) {
+	err := SendMail(&SMTPConfig{}, "user@example.com", "Subject", "Body")
+	assert.Error(t, err)
+}
+
+func TestUnitSendMailSilentServer(t *testing.T) {
+	// A server that accepts the connection but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	defer func(timeout time.Duration) { smtpSessionTimeout = timeout }(smtpSessionTimeout)
+	smtpSessionTimeout = 100 * time.Millisecond
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = SendMail(&SMTPConfig{Server: host, Port: port, FromEmail: "tut@example.com"}, "user@example.com", "Subject", "Body")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
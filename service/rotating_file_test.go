@@ -0,0 +1,52 @@
+// Copyright 2025 Clivern. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnitRotatingFile tests rotating a file once it reaches the size limit
+func TestUnitRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tut.log")
+
+	f, err := NewRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	defer f.Close()
+
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// Every write exceeds the limit so each line lands in its own file
+	assert.Equal(t, "line-4\n", read(path))
+	assert.Equal(t, "line-3\n", read(path+".1"))
+	assert.Equal(t, "line-2\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	t.Run("Existing size is kept when reopening", func(t *testing.T) {
+		reopened, err := NewRotatingFile(path, 10, 2)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		_, err = reopened.Write([]byte("line-5\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "line-5\n", read(path))
+		assert.Equal(t, "line-4\n", read(path+".1"))
+	})
+}
@@ -6,9 +6,12 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync/atomic"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -44,9 +47,8 @@ func GetValidator() *validator.Validate {
 // ValidationError represents a single validation error
 type ValidationError struct {
 	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
-	Tag     string `json:"tag,omitempty"`
-	Value   string `json:"value,omitempty"`
 }
 
 // ValidationErrors represents multiple validation errors
@@ -54,6 +56,14 @@ type ValidationErrors struct {
 	Errors []ValidationError `json:"errors"`
 }
 
+// Error returns the first validation error message
+func (v *ValidationErrors) Error() string {
+	if len(v.Errors) == 0 {
+		return "validation failed"
+	}
+	return v.Errors[0].Message
+}
+
 // ValidateStruct validates a struct and returns formatted errors
 func ValidateStruct(s interface{}) error {
 	return validate.Struct(s)
@@ -74,7 +84,7 @@ func getErrorMessage(e validator.FieldError) string {
 	field := e.Field()
 
 	switch e.Tag() {
-	case "required":
+	case "required", "required_if":
 		return fmt.Sprintf("%s is required", field)
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
@@ -121,12 +131,48 @@ func getErrorMessage(e validator.FieldError) string {
 	}
 }
 
+// strictJSON makes DecodeJSON reject fields the target struct does not have
+var strictJSON atomic.Bool
+
+// SetStrictJSON sets whether DecodeJSON rejects unknown fields. It is off by
+// default so clients sending extra fields keep working.
+func SetStrictJSON(strict bool) {
+	strictJSON.Store(strict)
+}
+
 // DecodeJSON decodes JSON from request body
+// Type mismatches, and unknown fields when strict JSON is on, are reported as ValidationErrors
 func DecodeJSON(r *http.Request, v interface{}) error {
-	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
-		return fmt.Errorf("Invalid JSON format: %w", err)
+	decoder := json.NewDecoder(r.Body)
+	if strictJSON.Load() {
+		decoder.DisallowUnknownFields()
 	}
-	return nil
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		label := fieldLabel(reflect.TypeOf(v), typeErr.Field)
+		return &ValidationErrors{Errors: []ValidationError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%s must be of type %s", label, jsonTypeName(typeErr.Type)),
+		}}}
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, "\"")
+		return &ValidationErrors{Errors: []ValidationError{{
+			Field:   field,
+			Rule:    "unknown",
+			Message: fmt.Sprintf("%s is not a known field", field),
+		}}}
+	}
+
+	return fmt.Errorf("Invalid JSON format: %w", err)
 }
 
 // DecodeAndValidate decodes JSON and validates the struct in one step
@@ -134,21 +180,171 @@ func DecodeAndValidate(r *http.Request, v interface{}) error {
 	if err := DecodeJSON(r, v); err != nil {
 		return err
 	}
-	return ValidateStruct(v)
+	if err := ValidateStruct(v); err != nil {
+		return NewValidationErrors(v, err)
+	}
+	return nil
 }
 
-// WriteValidationError writes validation errors as JSON response
-func WriteValidationError(w http.ResponseWriter, err error) {
-	w.Header().Set("Content-Type", "application/json")
+// NewValidationErrors converts validator errors for the struct v into
+// ValidationErrors keyed by the JSON field path
+func NewValidationErrors(v interface{}, err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
 
-	if validationErrs, ok := err.(validator.ValidationErrors); ok {
-		w.WriteHeader(http.StatusBadRequest)
-		WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": FormatValidationErrors(validationErrs),
-		})
-	} else {
-		WriteJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"errorMessage": err.Error(),
+	result := &ValidationErrors{Errors: make([]ValidationError, 0, len(validationErrs))}
+	for _, e := range validationErrs {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   jsonFieldPath(reflect.TypeOf(v), e.StructNamespace()),
+			Rule:    e.Tag(),
+			Message: getErrorMessage(e),
 		})
 	}
+
+	return result
+}
+
+// validationErrorResponse is the body of a validation error response
+type validationErrorResponse struct {
+	*APIError
+	Fields []ValidationError `json:"fields"`
+}
+
+// WriteValidationError writes validation errors as JSON response. A body
+// rejected by http.MaxBytesReader is answered with 413 instead.
+func WriteValidationError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		WriteErrorWithDetails(
+			w,
+			http.StatusRequestEntityTooLarge,
+			ErrorCodeRequestTooLarge,
+			fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit),
+			map[string]interface{}{"limit": maxBytesErr.Limit},
+		)
+		return
+	}
+
+	response := validationErrorResponse{
+		APIError: NewAPIError(ErrorCodeValidationFailed, err.Error()),
+		Fields:   []ValidationError{},
+	}
+	response.RequestID = w.Header().Get("X-Request-ID")
+
+	var validationErrs *ValidationErrors
+	if errors.As(err, &validationErrs) {
+		response.Fields = validationErrs.Errors
+	} else if errs, ok := err.(validator.ValidationErrors); ok {
+		response.Message = FormatValidationErrors(errs)
+	}
+
+	WriteJSON(w, http.StatusBadRequest, response)
+}
+
+// jsonFieldPath maps a struct namespace like "Request.Items[0].Name" to
+// the JSON path "items[0].name"
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+
+	path := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, index := part, ""
+		if i := strings.Index(part, "["); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+
+		t = indirectType(t)
+		if t.Kind() != reflect.Struct {
+			path = append(path, part)
+			continue
+		}
+
+		field, ok := t.FieldByName(name)
+		if !ok {
+			path = append(path, part)
+			continue
+		}
+
+		path = append(path, jsonName(field)+index)
+		t = field.Type
+		if index != "" {
+			t = indirectType(t).Elem()
+		}
+	}
+
+	return strings.Join(path, ".")
+}
+
+// fieldLabel returns the label of the field at the JSON path or the path itself
+func fieldLabel(t reflect.Type, jsonPath string) string {
+	var label string
+
+	for _, name := range strings.Split(jsonPath, ".") {
+		t = indirectType(t)
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = indirectType(t.Elem())
+		}
+		if t.Kind() != reflect.Struct {
+			return jsonPath
+		}
+
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if jsonName(field) == name {
+				label = field.Tag.Get("label")
+				t = field.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return jsonPath
+		}
+	}
+
+	if label == "" {
+		return jsonPath
+	}
+	return label
+}
+
+// jsonName returns the JSON name of a struct field
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// indirectType dereferences pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonTypeName returns a user-friendly name of the expected JSON type
+func jsonTypeName(t reflect.Type) string {
+	switch indirectType(t).Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
 }
@@ -5,9 +5,14 @@
 package service
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TestStruct struct {
@@ -142,3 +147,21 @@ func TestUnitFormatValidationErrors(t *testing.T) {
 	assert.NotEmpty(t, errorMsg)
 	assert.Contains(t, errorMsg, "email")
 }
+
+func TestUnitWriteValidationErrorBodyTooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a very long name"}`))
+	w := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(w, req.Body, 10)
+
+	var data TestStruct
+	err := DecodeAndValidate(req, &data)
+	require.Error(t, err)
+	WriteValidationError(w, err)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "request_too_large", response["errorCode"])
+	assert.Equal(t, map[string]interface{}{"limit": float64(10)}, response["details"])
+}